@@ -0,0 +1,30 @@
+package bindec
+
+import "strings"
+
+// ActivityMap returns, for each named field of Decoder d, how many times
+// its raw value changed between consecutive samples in vals, which helps
+// finding the busy bits of a capture. Field names are qualified by the
+// names of the enclosing groups, like "TEMP_STAT.TEMP". Fields that
+// didn't change at all are included with a count of zero.
+func ActivityMap(d Decoder, vals []int) map[string]int {
+	m := make(map[string]int)
+	walk(d, func(l leaf, at walkPos) {
+		f := l.field()
+		if f.Name == "" {
+			return
+		}
+		name := f.Name
+		if len(at.groups) != 0 {
+			name = strings.Join(at.groups, ".") + "." + name
+		}
+		n := 0
+		for i := 1; i < len(vals); i++ {
+			if at.extract(l, vals[i]) != at.extract(l, vals[i-1]) {
+				n++
+			}
+		}
+		m[name] += n
+	}, walkPos{})
+	return m
+}
@@ -0,0 +1,183 @@
+package bindec
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// An encoder is implemented by leaf Decoders that are able to
+// convert a textual field value back into the raw field value.
+type encoder interface {
+	encode(s string) (int, error)
+
+	// valueString returns the textual representation of
+	// a raw field value, as accepted by encode.
+	valueString(raw int) string
+}
+
+// Assemble is the inverse of decoding: It constructs a value from
+// a map of field names to field values, using the field definitions
+// of Decoder d. Values of Sig and Flag fields are parsed using
+// [strconv.ParseBool]; in case of a Flag named "!name", true
+// means that the field shall decode to name. Values of Val fields
+// may be one of the value names, or an integer; values of Int and Func
+// fields are integers as accepted by [strconv.ParseInt] with base 0.
+// Bits not covered by the specified fields are zero.
+func Assemble(d Decoder, fields map[string]string) (int, error) {
+	type target struct {
+		enc    encoder
+		pos    uint
+		max    int
+		invert bool // signal inverted by ActiveLow
+	}
+	targets := make(map[string]target)
+	walk(d, func(l leaf, at walkPos) {
+		enc, ok := l.(encoder)
+		if !ok {
+			return
+		}
+		f := l.field()
+		if _, dup := targets[f.Name]; dup || f.Name == "" {
+			return
+		}
+		_, isSig := l.(*signal)
+		targets[f.Name] = target{enc, f.StartBit + at.off, f.Mask() >> f.StartBit, isSig && at.activeLow}
+	}, walkPos{})
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	val := 0
+	for _, name := range names {
+		t, ok := targets[name]
+		if !ok {
+			return 0, fmt.Errorf("bindec: unknown field: %q", name)
+		}
+		raw, err := t.enc.encode(fields[name])
+		if err != nil {
+			return 0, fmt.Errorf("bindec: field %s: %w", name, err)
+		}
+		if raw < 0 || raw > t.max {
+			return 0, fmt.Errorf("bindec: field %s: value out of range: %d", name, raw)
+		}
+		if t.invert {
+			raw ^= 1
+		}
+		val |= raw << t.pos
+	}
+	return val, nil
+}
+
+// FieldValues is the inverse of Assemble: It returns the values of the
+// named fields of Decoder d contained in val, in a form accepted by
+// Assemble. Values of Int and Func fields are returned as decimal
+// integers. Fields that cannot be assembled are omitted.
+func FieldValues(d Decoder, val int) map[string]string {
+	m := make(map[string]string)
+	walk(d, func(l leaf, at walkPos) {
+		enc, ok := l.(encoder)
+		if !ok {
+			return
+		}
+		f := l.field()
+		if _, dup := m[f.Name]; dup || f.Name == "" {
+			return
+		}
+		m[f.Name] = enc.valueString(at.extract(l, val))
+	}, walkPos{})
+	return m
+}
+
+func (s *signal) encode(str string) (int, error) {
+	v, err := strconv.ParseBool(str)
+	if err != nil {
+		return 0, err
+	}
+	if s.negate {
+		v = !v
+	}
+	if v {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (s *signal) valueString(raw int) string {
+	return strconv.FormatBool(raw != 0 != s.negate)
+}
+
+func (v *value) encode(s string) (int, error) {
+	for i, name := range v.names {
+		if name == s && name != "" && name != "<reserved>" {
+			return i, nil
+		}
+	}
+	i, err := strconv.ParseInt(s, 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("unknown value: %q", s)
+	}
+	return int(i), nil
+}
+
+func (v *value) valueString(raw int) string {
+	if raw < len(v.names) {
+		if name := v.names[raw]; name != "" && name != "<reserved>" {
+			return name
+		}
+	}
+	return strconv.Itoa(raw)
+}
+
+func (v *intval) encode(s string) (int, error) {
+	i, err := strconv.ParseInt(s, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	return int(i), nil
+}
+
+func (v *intval) valueString(raw int) string {
+	return strconv.Itoa(raw)
+}
+
+// A Binding describes the state of a field within a value,
+// as needed for binding the field to an editor widget.
+type Binding struct {
+	Field
+
+	// Text is the formatted value of the field, as displayed
+	// when decoding, or "-", if the field doesn't produce any
+	// output for the value, like a cleared Sig field.
+	Text string
+
+	// Value is the value of the field in the form accepted by
+	// Assemble, if the field is writable.
+	Value string
+
+	Raw int // raw field value
+
+	// Writable reports whether the field can be assembled
+	// from a textual value using Assemble.
+	Writable bool
+}
+
+// DecodeBindings returns a Binding for each field of d, in declaration
+// order, describing its state within val. In contrast to decoding,
+// all fields are included, like cleared signals.
+func DecodeBindings(d Decoder, val int) []Binding {
+	var list []Binding
+	walk(d, func(l leaf, at walkPos) {
+		b := Binding{Field: absField(l, at), Raw: at.extract(l, val)}
+		b.Text = defaultOptions.leafText(l, at, val)
+		if enc, ok := l.(encoder); ok && b.Name != "" {
+			b.Value = enc.valueString(b.Raw)
+			b.Writable = true
+		}
+		list = append(list, b)
+	}, walkPos{})
+	return list
+}
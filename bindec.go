@@ -6,6 +6,7 @@ package bindec
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 // A Decoder appends the decoded representation of the
@@ -15,12 +16,178 @@ type Decoder interface {
 	Decode(w []string, val int) []string
 }
 
+// An entry is a single line of output produced by a Decoder,
+// together with information about the field it stems from.
+type entry struct {
+	name  string
+	value string
+	kv    bool // entry is to be rendered as "name: value"
+	depth int  // nesting level within groups
+
+	kind Kind
+	raw  int  // extracted field value
+	set  bool // state of a signal, considering negation
+
+	key string  // name for structured output, if different from name
+	tag string  // prepended to the line, before indentation
+	src Decoder // leaf Decoder, or group, that produced the entry
+
+	sev Severity
+
+	bits string // bit range, as shown if Options.ShowBits is set
+	mask int    // bits of the leaf field within the top-level value, if known
+	ref  string // reference to the leaf field, see Options.qualify
+
+	detail bool // detail line of a SigVerbose field
+}
+
+func (e *entry) isSig() bool {
+	return e.kind == SigKind || e.kind == FlagKind
+}
+
+// line returns the text of the entry, indented by tab
+// characters according to its depth.
+func (e *entry) line(o *Options) string {
+	s := e.text(o)
+	if o.Styler != nil && e.kind != GroupKind && e.kind != TextKind {
+		prefix, suffix := o.Styler.Style(e.keyName(), e.sev)
+		s = prefix + s + suffix
+	}
+	if e.bits != "" {
+		s = e.bits + " " + s
+	}
+	if e.depth == 0 && e.tag == "" {
+		return s
+	}
+	return e.tag + strings.Repeat("\t", e.depth) + s
+}
+
+// keyName returns the name of the entry as used in structured output.
+func (e *entry) keyName() string {
+	if e.key != "" {
+		return e.key
+	}
+	return e.name
+}
+
+func (e *entry) text(o *Options) string {
+	if e.kv {
+		return e.name + o.sep() + e.value
+	}
+	return e.name
+}
+
+// An entryDecoder is implemented by the Decoders of this package.
+// Instead of plain strings, it produces entries, so that
+// combinators have access to the structure of the output.
+type entryDecoder interface {
+	decodeEntries(e []entry, val int, o *Options) []entry
+}
+
+// decodeEntries decodes val using d. Decoders not implemented
+// by this package are wrapped, their output lines are
+// converted to entries.
+func decodeEntries(d Decoder, e []entry, val int, o *Options) []entry {
+	if o.failed != nil {
+		if *o.failed {
+			return e
+		}
+		defer func(n int) {
+			for i := n; i < len(e); i++ {
+				if e[i].sev >= SevWarning {
+					*o.failed = true
+					break
+				}
+			}
+		}(len(e))
+	}
+	l, isLeaf := d.(leaf)
+	if isLeaf && o.unknown != 0 {
+		if f, ok := o.absField(l); ok && f.Mask()&o.unknown != 0 {
+			return appendUnread(e, l, f.Mask(), o)
+		}
+	}
+	if isLeaf && o.Observer != nil {
+		t0 := time.Now()
+		defer func() {
+			o.Observer.Field(l.field().Name, time.Since(t0))
+		}()
+	}
+	n := len(e)
+	if ed, ok := d.(entryDecoder); ok {
+		e = ed.decodeEntries(e, val, o)
+	} else {
+		for _, s := range d.Decode(nil, val) {
+			e = append(e, entry{name: s})
+		}
+	}
+	if isLeaf {
+		var bits, ref string
+		mask := 0
+		if f, ok := o.absField(l); ok {
+			mask = f.Mask()
+			if o.ShowBits {
+				bits = bitsLabel(&f)
+			}
+			if o.qualify {
+				ref = fieldRef(&f)
+			}
+		}
+		for i := n; i < len(e); i++ {
+			e[i].src = l
+			if e[i].detail {
+				continue
+			}
+			e[i].bits = bits
+			e[i].mask = mask
+			e[i].ref = ref
+		}
+	}
+	return e
+}
+
+// absField returns the description of leaf l, with bit positions
+// relative to the top-level value, unless they are unknown.
+func (o *Options) absField(l leaf) (f Field, ok bool) {
+	if o.opaque {
+		return f, false
+	}
+	return absField(l, walkPos{off: o.off, groups: o.groups}), true
+}
+
+// bitsLabel returns the bit range of a field, like "[13:4]",
+// or "[bit 1]" for single-bit fields.
+func bitsLabel(f *Field) string {
+	if f.StartBit == f.EndBit {
+		return fmt.Sprintf("[bit %d]", f.StartBit)
+	}
+	return fmt.Sprintf("[%d:%d]", f.EndBit, f.StartBit)
+}
+
+// render appends the textual representation of the entries
+// to w, indenting them by tab characters according to their depth.
+func render(w []string, entries []entry, o *Options) []string {
+	for i := range entries {
+		e := &entries[i]
+		w = append(w, e.line(o))
+	}
+	return w
+}
+
+// bitMask returns a mask covering bit positions
+// startBit to, including, endBit.
+func bitMask(startBit, endBit uint) int {
+	return ((1 << (endBit + 1)) - 1) - ((1 << startBit) - 1)
+}
+
 type signal struct {
 	pos    uint
 	mask   int
 	name   string
 	isFlag bool
 	negate bool
+	sev    Severity
+	detail []string // see SigVerbose
 }
 
 // Sig defines a signal Decoder. If a value at bit
@@ -44,9 +211,24 @@ func Flag(pos uint, name string) Decoder {
 	return &signal{pos: pos, mask: 1 << pos, name: name, isFlag: true, negate: negate}
 }
 
-func (s *signal) Decode(w []string, val int) (list []string) {
+// SigVerbose defines a signal Decoder like Sig, that additionally
+// carries a detailed description of the signal. If Options.Verbose
+// is set, the lines of detail are emitted after the name,
+// indented by one more level.
+func SigVerbose(pos uint, name string, detail []string) Decoder {
+	return &signal{pos: pos, mask: 1 << pos, name: name, detail: detail}
+}
+
+func (s *signal) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, s, val)
+}
+
+func (s *signal) decodeEntries(e []entry, val int, o *Options) []entry {
 	var str string
-	list = w
+
+	if o.activeLow {
+		val ^= s.mask
+	}
 
 	v := val&s.mask != 0
 	if s.negate {
@@ -60,7 +242,7 @@ func (s *signal) Decode(w []string, val int) (list []string) {
 			str = "!" + s.name
 		}
 	case val&s.mask == 0:
-		return
+		return e
 	default:
 		if s.name == "<reserved>" {
 			str = fmt.Sprintf("bit %d: %s", s.pos, s.name)
@@ -68,8 +250,13 @@ func (s *signal) Decode(w []string, val int) (list []string) {
 			str = s.name
 		}
 	}
-	list = append(list, str)
-	return
+	e = append(e, entry{name: str, kind: s.field().Kind, raw: val & s.mask >> s.pos, set: v, key: s.name, sev: s.sev})
+	if o.Verbose {
+		for _, d := range s.detail {
+			e = append(e, entry{name: d, depth: 1, detail: true})
+		}
+	}
+	return e
 }
 
 type value struct {
@@ -78,6 +265,9 @@ type value struct {
 	desc  string
 	names []string
 	dflt  string
+
+	showBits bool
+	unknown  func(int) string // formats values not covered by names
 }
 
 // Val implements a value field Decoder. The value between
@@ -85,49 +275,67 @@ type value struct {
 // the corresponding element of the names slice,
 // using dflt if the slice is too short.
 func Val(startBit, endBit uint, desc string, names []string, dflt string) Decoder {
-	mask := ((1 << (endBit + 1)) - 1) - ((1 << startBit) - 1)
-	return &value{startBit, mask, desc, names, dflt}
+	return &value{pos: startBit, mask: bitMask(startBit, endBit), desc: desc, names: names, dflt: dflt}
+}
+
+func (v *value) Decode(w []string, b int) []string {
+	return defaultOptions.Decode(w, v, b)
 }
 
-func (v *value) Decode(w []string, b int) (list []string) {
+func (v *value) decodeEntries(e []entry, b int, o *Options) []entry {
 	b = b & v.mask >> v.pos
 
-	list = w
 	s := ""
 	switch {
 	case b < len(v.names):
 		s = v.names[b]
+		if s == "" && o.ShowUnnamed {
+			s = fmt.Sprintf("%d (unnamed)", b)
+		}
 	case v.dflt != "":
 		s = v.dflt
-	}
-	desc := v.desc
-	if desc != "" {
-		desc += ": "
+	case v.unknown != nil:
+		s = v.unknown(b)
 	}
 	switch s {
 	default:
-		list = append(list, desc+s)
 	case "<reserved>":
-		list = append(list, fmt.Sprintf("%s%d: %s", desc, b, s))
+		s = fmt.Sprintf("%d: %s", b, s)
 	case "":
+		return e
+	}
+	if v.showBits {
+		s += fmt.Sprintf(" (0b%0*b)", int(endBit(v.mask)-v.pos+1), b)
+	}
+	if v.desc == "" {
+		return append(e, entry{name: s, kind: ValKind, raw: b})
 	}
-	return
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: ValKind, raw: b})
 }
 
 type intval struct {
-	pos    uint
-	mask   int
-	desc   string
-	format string
-	f      func(int) string
+	pos     uint
+	mask    int
+	desc    string
+	format  string
+	f       func(int) string
+	counter bool // see IntCounter
 }
 
 // Int implements an integer Decoder. The value between
 // bit positions startBit and, including, endBit is formatted
 // using [fmt.Sprintf].
 func Int(startBit, endBit uint, desc string, format string) Decoder {
-	mask := ((1 << (endBit + 1)) - 1) - ((1 << startBit) - 1)
-	return &intval{startBit, mask, desc, format, nil}
+	return &intval{pos: startBit, mask: bitMask(startBit, endBit), desc: desc, format: format}
+}
+
+// IntCounter defines an integer Decoder like Int, for free-running
+// counters that wrap around. Decoding is the same as with Int, but Diff
+// computes the difference between two values modulo 2^width, where width
+// is the number of bits of the field, so that elapsed counts are
+// correct across wraps.
+func IntCounter(startBit, endBit uint, desc, format string) Decoder {
+	return &intval{pos: startBit, mask: bitMask(startBit, endBit), desc: desc, format: format, counter: true}
 }
 
 // Func defines an integer Decoder that, in contrast to Int,
@@ -135,26 +343,27 @@ func Int(startBit, endBit uint, desc string, format string) Decoder {
 // calls the specified function f to convert the integer value
 // between startBit and endBit to a string.
 func Func(startBit, endBit uint, desc string, f func(int) string) Decoder {
-	mask := ((1 << (endBit + 1)) - 1) - ((1 << startBit) - 1)
-	return &intval{startBit, mask, desc, "", f}
+	return &intval{pos: startBit, mask: bitMask(startBit, endBit), desc: desc, f: f}
+}
+
+func (v *intval) Decode(w []string, b int) []string {
+	return defaultOptions.Decode(w, v, b)
 }
 
-func (v *intval) Decode(w []string, b int) (list []string) {
+func (v *intval) decodeEntries(e []entry, b int, o *Options) []entry {
 	var s string
 
 	b = b & v.mask >> v.pos
 
 	if v.f == nil {
-		s = fmt.Sprintf(v.format, b)
+		s = o.sprintf(v.format, b)
 	} else {
 		s = v.f(b)
 	}
-	list = w
 	if v.desc == "" {
-		return
+		return e
 	}
-	list = append(list, v.desc+": "+s)
-	return
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: IntKind, raw: b})
 }
 
 // DecoderList defines a Decoder containing sub-Decoders.
@@ -169,10 +378,14 @@ func NewDecoderList(decoders ...[]Decoder) Decoder {
 }
 
 func (list DecoderList) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, list, val)
+}
+
+func (list DecoderList) decodeEntries(e []entry, val int, o *Options) []entry {
 	for _, d := range list {
-		w = d.Decode(w, val)
+		e = decodeEntries(d, e, val, o)
 	}
-	return w
+	return e
 }
 
 type shift struct {
@@ -186,29 +399,131 @@ func Shift(pos uint, d Decoder) Decoder {
 }
 
 func (s shift) Decode(w []string, val int) []string {
-	return s.d.Decode(w, val>>s.pos)
+	return defaultOptions.Decode(w, s, val)
+}
+
+func (s shift) decodeEntries(e []entry, val int, o *Options) []entry {
+	so := *o
+	so.off += s.pos
+	return decodeEntries(s.d, e, val>>s.pos, &so)
 }
 
 type group struct {
 	name string
+	agg  func(children []int) string
 	d    Decoder
 }
 
 // Group attaches a name to a sub-decoder.
 // The output of the sub-decoder gets indented by tab characters.
 func Group(name string, d Decoder) Decoder {
-	return &group{name, d}
+	return &group{name: name, d: d}
+}
+
+// GroupAggregate is like Group, but extends the header line
+// with the result of agg, which receives the raw values of
+// those direct children that are integer fields, as decoded
+// by Int or Func. This allows to display totals, maxima, or
+// a derived status next to the group's name, like
+// "ERRORS (total: 7)". In case agg returns an empty string,
+// the header consists of the name only.
+func GroupAggregate(name string, agg func(children []int) string, d Decoder) Decoder {
+	return &group{name: name, agg: agg, d: d}
 }
 
-func (g group) Decode(w []string, val int) []string {
-	sub := g.d.Decode(nil, val)
+func (g *group) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, g, val)
+}
+
+func (g *group) decodeEntries(e []entry, val int, o *Options) []entry {
+	sub := decodeEntries(g.d, nil, val, o.inGroup(g.name))
 	if sub == nil {
-		return w
-	} else {
-		w = append(w, g.name)
-		for _, s := range sub {
-			w = append(w, "\t"+s)
+		return e
+	}
+	header := g.name
+	if g.agg != nil {
+		var children []int
+		for i := range sub {
+			if c := &sub[i]; c.depth == 0 && c.kind == IntKind {
+				children = append(children, c.raw)
+			}
+		}
+		if s := g.agg(children); s != "" {
+			header += " " + s
 		}
 	}
-	return w
+	e = append(e, entry{name: header, kind: GroupKind, key: g.name, src: g})
+	for _, s := range sub {
+		s.depth++
+		e = append(e, s)
+	}
+	return e
+}
+
+type prefix struct {
+	name string
+	sep  string
+	d    Decoder
+}
+
+// Prefix is an alternative to Group: Instead of emitting a header
+// line and indenting the output of the sub-decoder d, name+sep is
+// prepended to each line of its output, like in "USB.CONFIGURED".
+// Nested prefixes accumulate. The "!" of negated flags stays
+// in front, like in "!USB.CONFIGURED".
+func Prefix(name, sep string, d Decoder) Decoder {
+	return &prefix{name, sep, d}
+}
+
+func (p *prefix) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, p, val)
+}
+
+func (p *prefix) decodeEntries(e []entry, val int, o *Options) []entry {
+	n := len(e)
+	e = decodeEntries(p.d, e, val, o.inGroup(p.name))
+	pfx := p.name + p.sep
+	for i := n; i < len(e); i++ {
+		c := &e[i]
+		if c.detail {
+			continue
+		}
+		c.key = pfx + c.keyName()
+		if c.isSig() && strings.HasPrefix(c.name, "!") {
+			c.name = "!" + pfx + c.name[1:]
+		} else {
+			c.name = pfx + c.name
+		}
+	}
+	return e
+}
+
+type source struct {
+	tag string
+	d   Decoder
+}
+
+// Source tags each line of the output of d, including group headers
+// and their indented children, with "[tag] ", which helps keeping track
+// of the originating register when merging the output of several
+// decoders into one log. The tag is placed before the indentation.
+func Source(tag string, d Decoder) Decoder {
+	return &source{"[" + tag + "] ", d}
+}
+
+func (s *source) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, s, val)
+}
+
+func (s *source) decodeEntries(e []entry, val int, o *Options) []entry {
+	n := len(e)
+	e = decodeEntries(s.d, e, val, o)
+	for i := n; i < len(e); i++ {
+		e[i].tag = s.tag + e[i].tag
+	}
+	return e
+}
+
+func (s *source) walk(fn walkFunc, at walkPos) {
+	walk(s.d, fn, at)
 }
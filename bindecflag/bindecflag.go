@@ -0,0 +1,58 @@
+// Package bindecflag provides a command-line interface for
+// assembling register values from the field definitions of a
+// [bindec.Decoder].
+package bindecflag
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/knieriem/bindec"
+)
+
+// A FlagSet contains one flag per field of a Decoder:
+// a boolean flag for each Sig or Flag field, a string flag
+// for each Val field, and an integer flag for each Int or Func field.
+type FlagSet struct {
+	*flag.FlagSet
+	d bindec.Decoder
+}
+
+// NewFlagSet returns a new FlagSet for the fields of Decoder d.
+// Fields without a name are skipped, as well as fields named
+// "<reserved>", reserved fields, fields that cannot be assembled
+// by [bindec.Assemble], like those defined by OneHot, and fields
+// whose name has already been used.
+func NewFlagSet(name string, d bindec.Decoder, errorHandling flag.ErrorHandling) *FlagSet {
+	fs := &FlagSet{FlagSet: flag.NewFlagSet(name, errorHandling), d: d}
+	for _, b := range bindec.DecodeBindings(d, 0) {
+		f := &b.Field
+		if !b.Writable || f.Name == "<reserved>" || f.Kind == bindec.ReservedKind || fs.Lookup(f.Name) != nil {
+			continue
+		}
+		usage := fmt.Sprintf("bit %d", f.StartBit)
+		if f.EndBit != f.StartBit {
+			usage = fmt.Sprintf("bits %d-%d", f.StartBit, f.EndBit)
+		}
+		switch f.Kind {
+		case bindec.SigKind, bindec.FlagKind:
+			fs.Bool(f.Name, false, "set "+usage)
+		case bindec.ValKind:
+			fs.String(f.Name, "", fmt.Sprintf("value of %s: %q", usage, f.Names))
+		default:
+			fs.Int(f.Name, 0, "integer value of "+usage)
+		}
+	}
+	return fs
+}
+
+// Value assembles a value from the flags that have been set,
+// using [bindec.Assemble]. It should be called after the
+// command-line has been parsed.
+func (fs *FlagSet) Value() (int, error) {
+	fields := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		fields[f.Name] = f.Value.String()
+	})
+	return bindec.Assemble(fs.d, fields)
+}
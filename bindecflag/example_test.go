@@ -0,0 +1,38 @@
+package bindecflag_test
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/knieriem/bindec"
+	"github.com/knieriem/bindec/bindecflag"
+)
+
+func ExampleFlagSet() {
+	d := bindec.DecoderList{
+		bindec.Flag(0, "EN"),
+		bindec.Val(1, 2, "MODE", []string{"OFF", "SLOW", "FAST"}, ""),
+		bindec.Int(4, 7, "DIV", "%d"),
+		bindec.OneHot(8, 11, "VEC", nil),
+	}
+	fs := bindecflag.NewFlagSet("reg", d, flag.ContinueOnError)
+	fmt.Println(fs.Lookup("VEC") == nil)
+
+	err := fs.Parse([]string{"-EN", "-MODE=FAST", "-DIV=5"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	val, err := fs.Value()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%#x\n", val)
+	fmt.Println(d.Decode(nil, val))
+
+	// Output:
+	// true
+	// 0x55
+	// [EN MODE: FAST DIV: 5 VEC: WARNING: no bit set]
+}
@@ -0,0 +1,109 @@
+// Package bindectest provides helpers for testing
+// the definitions of [bindec.Decoder] trees.
+package bindectest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/knieriem/bindec"
+)
+
+// exhaustiveWidth is the maximum width of values
+// that are checked exhaustively.
+const exhaustiveWidth = 16
+
+// RoundTrip verifies that, for values of the specified width,
+// converting the field values of a value, as returned by
+// [bindec.FieldValues], back using [bindec.Assemble] reproduces
+// the original value with regard to the bits covered by the
+// assemblable fields of d. Reserved and undefined bits are ignored.
+// For widths up to 16 bits all values are checked, for larger
+// widths a sample of values.
+func RoundTrip(t testing.TB, d bindec.Decoder, width uint) {
+	t.Helper()
+
+	covered := 0
+	names := bindec.FieldValues(d, 0)
+	for _, f := range bindec.Fields(d) {
+		if _, ok := names[f.Name]; ok {
+			covered |= f.Mask()
+			delete(names, f.Name)
+		}
+	}
+	for _, val := range sample(width) {
+		got, err := bindec.Assemble(d, bindec.FieldValues(d, val))
+		if err != nil {
+			t.Errorf("value %#x: %v", val, err)
+			continue
+		}
+		if got&covered != val&covered {
+			t.Errorf("value %#x: round trip results in %#x (covered bits %#x)", val, got, covered)
+		}
+	}
+}
+
+// AssertDecode verifies that decoding val using d results
+// in exactly the lines of want, in the same order.
+func AssertDecode(t testing.TB, d bindec.Decoder, val int, want []string) {
+	t.Helper()
+
+	got := d.Decode(nil, val)
+	equal := len(got) == len(want)
+	for i := 0; equal && i < len(got); i++ {
+		equal = got[i] == want[i]
+	}
+	if !equal {
+		t.Errorf("value %#x: got %q, want %q", val, got, want)
+	}
+}
+
+// AssertDecodeSet verifies that decoding val using d results in the
+// lines of want, regardless of their order, which is more robust
+// against changes of the declaration order than AssertDecode.
+// Failures list the missing and the unexpected lines.
+func AssertDecodeSet(t testing.TB, d bindec.Decoder, val int, want []string) {
+	t.Helper()
+
+	count := make(map[string]int)
+	for _, s := range want {
+		count[s]++
+	}
+	var extra, missing []string
+	for _, s := range d.Decode(nil, val) {
+		if count[s] == 0 {
+			extra = append(extra, s)
+			continue
+		}
+		count[s]--
+	}
+	for _, s := range want {
+		if count[s] != 0 {
+			missing = append(missing, s)
+			count[s]--
+		}
+	}
+	if missing != nil || extra != nil {
+		t.Errorf("value %#x: missing lines %q, unexpected lines %q", val, missing, extra)
+	}
+}
+
+func sample(width uint) []int {
+	max := 1<<width - 1
+	if width <= exhaustiveWidth {
+		vals := make([]int, 0, max+1)
+		for v := 0; v <= max; v++ {
+			vals = append(vals, v)
+		}
+		return vals
+	}
+	vals := []int{0, max}
+	for i := uint(0); i < width; i++ {
+		vals = append(vals, 1<<i, max&^(1<<i))
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		vals = append(vals, int(r.Uint64())&max)
+	}
+	return vals
+}
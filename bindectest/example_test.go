@@ -0,0 +1,52 @@
+package bindectest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/knieriem/bindec"
+	"github.com/knieriem/bindec/bindectest"
+)
+
+// reporter is a testing.TB printing the reported errors,
+// instead of failing a test.
+type reporter struct {
+	testing.TB
+}
+
+func (reporter) Helper() {}
+
+func (reporter) Errorf(format string, args ...interface{}) {
+	fmt.Printf(format+"\n", args...)
+}
+
+func ExampleRoundTrip() {
+	good := bindec.DecoderList{
+		bindec.Flag(0, "EN"),
+		bindec.Val(1, 2, "MODE", []string{"OFF", "SLOW", "FAST"}, ""),
+		bindec.Int(4, 7, "DIV", "%d"),
+	}
+	bindectest.RoundTrip(reporter{}, good, 8)
+
+	// both codes are named OFF, so code 1 is assembled as 0
+	bad := bindec.Val(0, 0, "MODE", []string{"OFF", "OFF"}, "")
+	bindectest.RoundTrip(reporter{}, bad, 1)
+
+	// Output:
+	// value 0x1: round trip results in 0x0 (covered bits 0x1)
+}
+
+func ExampleAssertDecode() {
+	d := bindec.DecoderList{
+		bindec.Sig(0, "RDY"),
+		bindec.Sig(1, "ERR"),
+	}
+	bindectest.AssertDecode(reporter{}, d, 0x3, []string{"RDY", "ERR"})
+	bindectest.AssertDecode(reporter{}, d, 0x3, []string{"ERR", "RDY"})
+	bindectest.AssertDecodeSet(reporter{}, d, 0x3, []string{"ERR", "RDY"})
+	bindectest.AssertDecodeSet(reporter{}, d, 0x1, []string{"ERR", "RDY"})
+
+	// Output:
+	// value 0x3: got ["RDY" "ERR"], want ["ERR" "RDY"]
+	// value 0x1: missing lines ["ERR"], unexpected lines []
+}
@@ -0,0 +1,153 @@
+package bindec
+
+import "math/bits"
+
+// A BitOrder specifies how bit positions, as used by
+// the leaf Decoders, map to the bits of a value.
+type BitOrder int
+
+const (
+	// LSBFirst numbers bits starting with the least significant
+	// bit of the value; it is the default numbering.
+	LSBFirst BitOrder = iota
+
+	// MSBFirst numbers bits starting with the most
+	// significant bit of a value of the specified width.
+	MSBFirst
+
+	// MixedByteLSB numbers bytes starting with the least
+	// significant byte, but bits within each byte starting
+	// with the most significant bit, as found in certain
+	// serial standards.
+	MixedByteLSB
+)
+
+type bitOrder struct {
+	order BitOrder
+	width uint
+	d     Decoder
+}
+
+// WithBitOrder defines a Decoder that interprets bit positions within d
+// according to the specified order, for values of the specified width.
+// The value is remapped accordingly before it is passed to d.
+// As the bit positions within d don't correspond to those of the
+// value, the fields of d are opaque to functions inspecting a Decoder
+// tree: They are not reported by Fields, and not considered by ExtractInt,
+// Explain, or Diff, for instance. Likewise, options depending on the
+// position of fields, like ShowBits, don't apply to them.
+func WithBitOrder(order BitOrder, width uint, d Decoder) Decoder {
+	return &bitOrder{order, width, d}
+}
+
+func (b *bitOrder) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, b, val)
+}
+
+func (b *bitOrder) decodeEntries(e []entry, val int, o *Options) []entry {
+	bo := *o
+	bo.opaque = true
+	return decodeEntries(b.d, e, b.remap(val), &bo)
+}
+
+// remap returns val with its bits rearranged, so
+// that the bit at position i of the result is the
+// bit numbered i according to the bit order.
+func (b *bitOrder) remap(val int) int {
+	if b.order == LSBFirst {
+		return val
+	}
+	v := 0
+	for i := uint(0); i < b.width; i++ {
+		var p uint
+		switch b.order {
+		case MSBFirst:
+			p = b.width - 1 - i
+		case MixedByteLSB:
+			p = i&^7 + 7 - i&7
+		default:
+			p = i
+		}
+		v |= (val >> p & 1) << i
+	}
+	return v
+}
+
+type bitReverse struct {
+	pos   uint
+	mask  int
+	width uint
+	desc  string
+	inner Decoder
+}
+
+// BitReverse defines a Decoder for fields that arrive bit-reversed,
+// like shift register readouts with swapped MSB and LSB. The value
+// between startBit and, including, endBit is extracted, its bits are
+// reversed within the width of the field, and the result is decoded
+// by inner, which typically is an Int or Val starting at bit 0.
+// As a field, as reported by Fields, it is named desc.
+func BitReverse(startBit, endBit uint, desc string, inner Decoder) Decoder {
+	return &bitReverse{startBit, bitMask(startBit, endBit), endBit - startBit + 1, desc, inner}
+}
+
+func (r *bitReverse) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, r, val)
+}
+
+func (r *bitReverse) decodeEntries(e []entry, val int, o *Options) []entry {
+	ro := *o
+	ro.opaque = true
+	return decodeEntries(r.inner, e, r.extract(val), &ro)
+}
+
+func (r *bitReverse) extract(val int) int {
+	b := uint(val & r.mask >> r.pos)
+	return int(bits.Reverse(b) >> (bits.UintSize - r.width))
+}
+
+func (r *bitReverse) field() Field {
+	k := IntKind
+	if l, ok := r.inner.(leaf); ok {
+		k = l.field().Kind
+	}
+	return Field{Name: r.desc, Kind: k, StartBit: r.pos, EndBit: endBit(r.mask)}
+}
+
+type complement struct {
+	mask int
+	d    Decoder
+}
+
+// Complement defines a Decoder that decodes the bitwise complement of
+// a value using d, prefixing each line with "~", like "~OVERTEMP".
+// Used next to d within a DecoderList, it shows both the active-high
+// and the active-low interpretation of a value. The complement is masked
+// to the width declared for d using WithWidth; if no width has been
+// declared, the width resulting from MaxBit is used. As Complement only
+// provides an alternative view, its fields are not reported by Fields.
+func Complement(d Decoder) Decoder {
+	w, ok := Width(d)
+	if !ok {
+		w = MaxBit(d) + 1
+	}
+	return &complement{1<<w - 1, d}
+}
+
+func (c *complement) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, c, val)
+}
+
+func (c *complement) decodeEntries(e []entry, val int, o *Options) []entry {
+	n := len(e)
+	e = decodeEntries(c.d, e, ^val&c.mask, o)
+	for i := n; i < len(e); i++ {
+		x := &e[i]
+		if x.detail {
+			continue
+		}
+		x.key = "~" + x.keyName()
+		x.name = "~" + x.name
+	}
+	return e
+}
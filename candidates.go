@@ -0,0 +1,60 @@
+package bindec
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Candidates supports exploring hypothesized layouts of an unknown value.
+// The returned function decodes a value using each of the named layouts,
+// and returns the results by name.
+func Candidates(layouts map[string]Decoder) func(val int) map[string][]string {
+	return func(val int) map[string][]string {
+		m := make(map[string][]string, len(layouts))
+		for name, d := range layouts {
+			m[name] = d.Decode(nil, val)
+		}
+		return m
+	}
+}
+
+// RankCandidates returns the names of the layouts, ordered by how well
+// they match val, best first. Layouts producing fewer warnings and
+// errors, like set reserved bits, rank higher; layouts with the same
+// number of warnings are ranked by the number of bits set in val,
+// considering the specified width, that are not covered by any of their
+// fields (see Coverage). Remaining ties are ordered by name.
+func RankCandidates(layouts map[string]Decoder, val int, width uint) []string {
+	type score struct {
+		name      string
+		warnings  int
+		unmatched int
+	}
+	scores := make([]score, 0, len(layouts))
+	for name, d := range layouts {
+		s := score{name: name}
+		for _, e := range decodeEntries(d, nil, val, &defaultOptions) {
+			if e.sev >= SevWarning {
+				s.warnings++
+			}
+		}
+		_, uncovered := Coverage(d, width)
+		s.unmatched = bits.OnesCount(uint(val & uncovered))
+		scores = append(scores, s)
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		a, b := &scores[i], &scores[j]
+		switch {
+		case a.warnings != b.warnings:
+			return a.warnings < b.warnings
+		case a.unmatched != b.unmatched:
+			return a.unmatched < b.unmatched
+		}
+		return a.name < b.name
+	})
+	names := make([]string, len(scores))
+	for i := range scores {
+		names[i] = scores[i].name
+	}
+	return names
+}
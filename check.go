@@ -0,0 +1,220 @@
+package bindec
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+type exclusive struct {
+	desc string
+	list DecoderList
+}
+
+// Exclusive defines a Decoder for mutually exclusive signals.
+// It decodes val using each of the specified decoders, usually Sig
+// or Flag decoders. In case more than one of them reports a set
+// signal, a line "WARNING: mutually exclusive flags set: A, B"
+// is appended after the regular output, with desc inserted after
+// "WARNING: ", if not empty. Decoders other than Sig and Flag are
+// considered active if they produce any output.
+func Exclusive(desc string, decoders ...Decoder) Decoder {
+	return &exclusive{desc, DecoderList(decoders)}
+}
+
+func (x *exclusive) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, x, val)
+}
+
+func (x *exclusive) decodeEntries(e []entry, val int, o *Options) []entry {
+	var active []string
+	for _, d := range x.list {
+		n := len(e)
+		e = decodeEntries(d, e, val, o)
+		for i := n; i < len(e); i++ {
+			if c := &e[i]; !c.isSig() || c.set {
+				active = append(active, c.keyName())
+				break
+			}
+		}
+	}
+	if len(active) > 1 {
+		s := "WARNING: "
+		if x.desc != "" {
+			s += x.desc + ": "
+		}
+		s += "mutually exclusive flags set: " + strings.Join(active, ", ")
+		e = append(e, entry{name: s, sev: SevWarning})
+	}
+	return e
+}
+
+func (x *exclusive) walk(fn walkFunc, at walkPos) {
+	x.list.walk(fn, at)
+}
+
+type crc struct {
+	pos     uint
+	mask    int
+	width   uint
+	covered int
+	poly    uint
+	desc    string
+}
+
+// CRC defines a Decoder that verifies a CRC field between crcStart
+// and, including, crcEnd. The CRC, having the width of the field, is
+// computed over the bits of coveredMask, most significant bit first,
+// using the polynomial poly in normal representation (without the
+// leading one), and an initial value of zero. Depending on whether
+// the result matches the stored CRC, the output is "desc: OK",
+// or "desc: BAD (got 0x.., want 0x..)".
+func CRC(crcStart, crcEnd uint, coveredMask int, poly uint, desc string) Decoder {
+	return &crc{
+		pos:     crcStart,
+		mask:    bitMask(crcStart, crcEnd),
+		width:   crcEnd - crcStart + 1,
+		covered: coveredMask,
+		poly:    poly,
+		desc:    desc,
+	}
+}
+
+func (c *crc) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, c, val)
+}
+
+func (c *crc) decodeEntries(e []entry, val int, o *Options) []entry {
+	got := val & c.mask >> c.pos
+	want := int(c.compute(val))
+	s := "OK"
+	sev := SevInfo
+	if got != want {
+		s = fmt.Sprintf("BAD (got %#x, want %#x)", got, want)
+		sev = SevError
+	}
+	if c.desc == "" {
+		return append(e, entry{name: s, kind: IntKind, raw: got, sev: sev})
+	}
+	return append(e, entry{name: c.desc, value: s, kv: true, kind: IntKind, raw: got, sev: sev})
+}
+
+func (c *crc) compute(val int) uint {
+	top := uint(1) << (c.width - 1)
+	all := top<<1 - 1
+	reg := uint(0)
+	for i := int(endBit(c.covered)); i >= 0; i-- {
+		if c.covered&(1<<uint(i)) == 0 {
+			continue
+		}
+		bit := uint(val>>uint(i)) & 1
+		if (reg&top != 0) != (bit != 0) {
+			reg = (reg<<1 ^ c.poly) & all
+		} else {
+			reg = reg << 1 & all
+		}
+	}
+	return reg
+}
+
+func (c *crc) field() Field {
+	return Field{Name: c.desc, Kind: IntKind, StartBit: c.pos, EndBit: endBit(c.mask)}
+}
+
+// A ThresholdDecoder counts the set bits of a field
+// and reports whether a threshold is exceeded.
+type ThresholdDecoder struct {
+	pos       uint
+	mask      int
+	desc      string
+	threshold int
+
+	// Marker is appended to the output when the
+	// threshold is exceeded, by default "EXCEEDED".
+	Marker string
+
+	// If OrEqual is set, a count equal to the threshold
+	// is considered exceeding it as well.
+	OrEqual bool
+}
+
+// Threshold returns a Decoder that counts the bits set between startBit
+// and, including, endBit. If the count is larger than threshold, the
+// output looks like "FAULTS: 3 (threshold 2 EXCEEDED)", otherwise
+// just the count is displayed. The marker and the comparison can be
+// configured using the fields of the returned ThresholdDecoder.
+func Threshold(startBit, endBit uint, desc string, threshold int) *ThresholdDecoder {
+	return &ThresholdDecoder{
+		pos:       startBit,
+		mask:      bitMask(startBit, endBit),
+		desc:      desc,
+		threshold: threshold,
+		Marker:    "EXCEEDED",
+	}
+}
+
+func (t *ThresholdDecoder) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, t, val)
+}
+
+func (t *ThresholdDecoder) decodeEntries(e []entry, val int, o *Options) []entry {
+	n := bits.OnesCount(uint(val & t.mask))
+	s := strconv.Itoa(n)
+	sev := SevInfo
+	if n > t.threshold || t.OrEqual && n == t.threshold {
+		s += fmt.Sprintf(" (threshold %d %s)", t.threshold, t.Marker)
+		sev = SevWarning
+	}
+	if t.desc == "" {
+		return append(e, entry{name: s, kind: IntKind, raw: n, sev: sev})
+	}
+	return append(e, entry{name: t.desc, value: s, kv: true, kind: IntKind, raw: n, sev: sev})
+}
+
+func (t *ThresholdDecoder) field() Field {
+	return Field{Name: t.desc, Kind: IntKind, StartBit: t.pos, EndBit: endBit(t.mask)}
+}
+
+type pair struct {
+	up, down *signal
+}
+
+// Pair defines a Decoder for two complementary signals, of which exactly
+// one is expected to be set, like UP and DOWN direction bits. Normally,
+// the name of the active signal is emitted. If both bits are set, both
+// names are emitted, followed by a warning like "WARNING: inconsistent
+// state: UP (bit 3) and DOWN (bit 4) both set"; if neither is set,
+// a corresponding warning is emitted instead.
+func Pair(upPos, downPos uint, upName, downName string) Decoder {
+	return &pair{
+		up:   &signal{pos: upPos, mask: 1 << upPos, name: upName},
+		down: &signal{pos: downPos, mask: 1 << downPos, name: downName},
+	}
+}
+
+func (p *pair) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, p, val)
+}
+
+func (p *pair) decodeEntries(e []entry, val int, o *Options) []entry {
+	e = decodeEntries(p.up, e, val, o)
+	e = decodeEntries(p.down, e, val, o)
+
+	up, down := val&p.up.mask != 0 != o.activeLow, val&p.down.mask != 0 != o.activeLow
+	if up != down {
+		return e
+	}
+	s := "WARNING: inconsistent state: "
+	if up {
+		s += fmt.Sprintf("%s (bit %d) and %s (bit %d) both set", p.up.name, p.up.pos, p.down.name, p.down.pos)
+	} else {
+		s += fmt.Sprintf("neither %s (bit %d) nor %s (bit %d) set", p.up.name, p.up.pos, p.down.name, p.down.pos)
+	}
+	return append(e, entry{name: s, sev: SevWarning})
+}
+
+func (p *pair) walk(fn walkFunc, at walkPos) {
+	fn(p.up, at)
+	fn(p.down, at)
+}
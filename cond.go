@@ -0,0 +1,319 @@
+package bindec
+
+type withState struct {
+	state int
+	d     Decoder
+}
+
+// WithState supplies an external state to Decoder d, for
+// fields whose interpretation depends on context not contained
+// in the value itself, like a mode stored elsewhere. Within d,
+// the state can be consulted using WhenState; it overrides the
+// value of Options.State.
+func WithState(state int, d Decoder) Decoder {
+	return &withState{state, d}
+}
+
+func (s *withState) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, s, val)
+}
+
+func (s *withState) decodeEntries(e []entry, val int, o *Options) []entry {
+	so := *o
+	so.State = s.state
+	return decodeEntries(s.d, e, val, &so)
+}
+
+func (s *withState) walk(fn walkFunc, at walkPos) {
+	walk(s.d, fn, at)
+}
+
+type whenState struct {
+	cond func(state int) bool
+	d    Decoder
+}
+
+// WhenState defines a Decoder that decodes a value using d
+// only if cond returns true for the external state, as supplied
+// by WithState or Options.State.
+func WhenState(cond func(state int) bool, d Decoder) Decoder {
+	return &whenState{cond, d}
+}
+
+func (s *whenState) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, s, val)
+}
+
+func (s *whenState) decodeEntries(e []entry, val int, o *Options) []entry {
+	if !s.cond(o.State) {
+		return e
+	}
+	return decodeEntries(s.d, e, val, o)
+}
+
+func (s *whenState) walk(fn walkFunc, at walkPos) {
+	walk(s.d, fn, at.conditional(s))
+}
+
+type optional struct {
+	presentIf func(val int) bool
+	d         Decoder
+}
+
+// Optional defines an optional field, or group of fields, as found in
+// extensible protocol headers: Decoder d is used only if presentIf
+// returns true for the value, typically testing a length or flags field;
+// otherwise nothing is emitted.
+func Optional(presentIf func(val int) bool, d Decoder) Decoder {
+	return &optional{presentIf, d}
+}
+
+// OptionalVal defines optional fields decoded by d,
+// that are present if the bit at position selBit is set.
+func OptionalVal(selBit uint, d Decoder) Decoder {
+	return Optional(func(val int) bool {
+		return val&(1<<selBit) != 0
+	}, d)
+}
+
+// OnlyWhen defines a Decoder that stays silent unless match returns
+// true for a value, like a fault condition, so that decoders for
+// high-frequency polling don't produce noise. It behaves like Optional.
+func OnlyWhen(match func(val int) bool, d Decoder) Decoder {
+	return &optional{match, d}
+}
+
+// Match returns a predicate for use with OnlyWhen or Optional,
+// that reports whether the bits of mask within a value equal those of want.
+func Match(mask, want int) func(val int) bool {
+	return func(val int) bool {
+		return val&mask == want&mask
+	}
+}
+
+func (x *optional) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, x, val)
+}
+
+func (x *optional) decodeEntries(e []entry, val int, o *Options) []entry {
+	if !x.presentIf(val) {
+		return e
+	}
+	return decodeEntries(x.d, e, val, o)
+}
+
+func (x *optional) walk(fn walkFunc, at walkPos) {
+	walk(x.d, fn, at.conditional(x))
+}
+
+type pipe struct {
+	extract Decoder
+	next    func(int) Decoder
+}
+
+// Pipe defines a two-stage Decoder for layered encodings. The raw value
+// of the first field defined by extract is obtained the way ExtractInt
+// does; then the Decoder returned by next for that value is used to
+// decode it. Only the output of the second stage is emitted;
+// if extract defines no field, or next returns nil, nothing is emitted.
+func Pipe(extract Decoder, next func(int) Decoder) Decoder {
+	return &pipe{extract, next}
+}
+
+func (p *pipe) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, p, val)
+}
+
+func (p *pipe) decodeEntries(e []entry, val int, o *Options) []entry {
+	found := false
+	v := 0
+	walk(p.extract, func(l leaf, at walkPos) {
+		if !found {
+			v, found = at.extract(l, val), true
+		}
+	}, walkPos{})
+	if !found {
+		return e
+	}
+	d := p.next(v)
+	if d == nil {
+		return e
+	}
+	po := *o
+	po.opaque = true
+	return decodeEntries(d, e, v, &po)
+}
+
+type versioned struct {
+	rev      func(val int) int
+	versions map[int]Decoder
+	dflt     Decoder
+}
+
+// Versioned defines a Decoder for registers whose layout changed
+// across revisions. The revision returned by revSelector for a value
+// selects the Decoder from versions; for unknown revisions dflt is used,
+// which may be nil. If revSelector is nil, the external state, as
+// supplied by WithState or Options.State, is used as revision.
+// Functions inspecting a Decoder tree, like Fields,
+// consider dflt only.
+func Versioned(revSelector func(val int) int, versions map[int]Decoder, dflt Decoder) Decoder {
+	return &versioned{revSelector, versions, dflt}
+}
+
+func (v *versioned) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *versioned) decodeEntries(e []entry, val int, o *Options) []entry {
+	rev := o.State
+	if v.rev != nil {
+		rev = v.rev(val)
+	}
+	d, ok := v.versions[rev]
+	if !ok {
+		d = v.dflt
+	}
+	if d == nil {
+		return e
+	}
+	return decodeEntries(d, e, val, o)
+}
+
+func (v *versioned) walk(fn walkFunc, at walkPos) {
+	if v.dflt != nil {
+		walk(v.dflt, fn, at)
+	}
+}
+
+type gated struct {
+	enable   int
+	useState bool
+	d        Decoder
+}
+
+// Gated defines a Decoder for interrupt status registers accompanied by
+// an enable register: Within the output of statusDecoder, Sig and Flag
+// fields whose bit is not set in enableMask are suppressed, so that
+// only enabled interrupts are displayed. Groups that end up empty
+// are omitted.
+func Gated(statusDecoder Decoder, enableMask int) Decoder {
+	return &gated{enable: enableMask, d: statusDecoder}
+}
+
+// GatedState is like Gated, but the enable mask is taken from the
+// external state, as supplied by WithState or Options.State, so that
+// it can vary from read to read.
+func GatedState(statusDecoder Decoder) Decoder {
+	return &gated{useState: true, d: statusDecoder}
+}
+
+func (g *gated) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, g, val)
+}
+
+func (g *gated) decodeEntries(e []entry, val int, o *Options) []entry {
+	enable := g.enable
+	if g.useState {
+		enable = o.State
+	}
+	n := len(e)
+	e = decodeEntries(g.d, e, val, o)
+	enable <<= o.off
+	return filterLeaves(e, n, func(l leaf, m int) bool {
+		if k := l.field().Kind; m == 0 || k != SigKind && k != FlagKind {
+			return true
+		}
+		return m&enable != 0
+	})
+}
+
+// filterLeaves removes the entries following e[:n] that stem from
+// leaves for which keep returns false; keep receives the bits of
+// the leaf within the top-level value, or zero, if they are unknown.
+// Detail lines share the fate of the line they belong to. Groups
+// that end up empty are removed as well.
+func filterLeaves(e []entry, n int, keep func(l leaf, mask int) bool) []entry {
+	out := e[:n]
+	drop := false
+	for i := n; i < len(e); i++ {
+		if !e[i].detail {
+			l, ok := e[i].src.(leaf)
+			drop = ok && !keep(l, e[i].mask)
+		}
+		if drop {
+			continue
+		}
+		out = append(out, e[i])
+	}
+	return pruneGroups(out, n)
+}
+
+type window struct {
+	mask int
+	d    Decoder
+}
+
+// Window defines a Decoder that decodes only those fields of d that lie
+// within the bits between startBit and, including, endBit, which
+// allows to focus on a part of a larger register using a decoder for
+// the whole register. Bits outside the window are cleared before
+// decoding. Fields straddling the window boundaries are omitted.
+func Window(startBit, endBit uint, d Decoder) Decoder {
+	return &window{bitMask(startBit, endBit), d}
+}
+
+func (x *window) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, x, val)
+}
+
+func (x *window) decodeEntries(e []entry, val int, o *Options) []entry {
+	n := len(e)
+	e = decodeEntries(x.d, e, val&x.mask, o)
+	mask := x.mask << o.off
+	return filterLeaves(e, n, func(_ leaf, m int) bool {
+		return m&^mask == 0
+	})
+}
+
+func (x *window) walk(fn walkFunc, at walkPos) {
+	walk(x.d, func(l leaf, inner walkPos) {
+		if f := absField(l, inner); f.Mask()>>at.off&^x.mask == 0 {
+			fn(l, inner)
+		}
+	}, at)
+}
+
+func (g *gated) walk(fn walkFunc, at walkPos) {
+	walk(g.d, fn, at)
+}
+
+type activeLow struct {
+	d Decoder
+}
+
+// ActiveLow inverts the sense of the Sig and Flag fields within d,
+// so that a cleared bit is treated as set, and vice versa, which
+// is convenient for register blocks using negative logic. Other
+// fields, like those defined by Val or Int, are not affected.
+// Nested ActiveLow decoders cancel each other out. Functions
+// inspecting a Decoder tree, like Explain or Assemble,
+// consider the inversion as well.
+func ActiveLow(d Decoder) Decoder {
+	return &activeLow{d}
+}
+
+func (a *activeLow) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, a, val)
+}
+
+func (a *activeLow) decodeEntries(e []entry, val int, o *Options) []entry {
+	ao := *o
+	ao.activeLow = !o.activeLow
+	return decodeEntries(a.d, e, val, &ao)
+}
+
+func (a *activeLow) walk(fn walkFunc, at walkPos) {
+	at.activeLow = !at.activeLow
+	walk(a.d, fn, at)
+}
@@ -0,0 +1,133 @@
+package bindec
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A CSVWriter writes decoded values as CSV records, one record per value,
+// and one column per field of a Decoder. Column names are qualified by
+// the names of the enclosing groups, like "TEMP_STAT.TEMP". Sig and Flag
+// fields result in 0 or 1, depending on whether the field decodes to
+// its name; other fields are displayed in their formatted form, like
+// the names of Val fields.
+type CSVWriter struct {
+	w       *csv.Writer
+	cols    []csvColumn
+	started bool
+}
+
+type csvColumn struct {
+	name string
+	l    leaf
+	at   walkPos
+}
+
+// NewCSVWriter returns a CSVWriter writing to w, for
+// values decoded using d.
+func NewCSVWriter(w io.Writer, d Decoder) *CSVWriter {
+	cw := &CSVWriter{w: csv.NewWriter(w)}
+	walk(d, func(l leaf, at walkPos) {
+		f := l.field()
+		if f.Name == "" || f.Name == "<reserved>" {
+			return
+		}
+		name := f.Name
+		if len(at.groups) != 0 {
+			name = strings.Join(at.groups, ".") + "." + name
+		}
+		cw.cols = append(cw.cols, csvColumn{name, l, at})
+	}, walkPos{})
+	return cw
+}
+
+// Write writes a record containing the fields of val. The header
+// record containing the column names is written before the first one.
+func (cw *CSVWriter) Write(val int) error {
+	if !cw.started {
+		cw.started = true
+		header := make([]string, len(cw.cols))
+		for i := range cw.cols {
+			header[i] = cw.cols[i].name
+		}
+		if err := cw.w.Write(header); err != nil {
+			return err
+		}
+	}
+	rec := make([]string, len(cw.cols))
+	for i := range cw.cols {
+		rec[i] = cw.cols[i].value(val)
+	}
+	return cw.w.Write(rec)
+}
+
+// Flush writes any buffered data to the underlying io.Writer,
+// and reports any error that occurred during writing.
+func (cw *CSVWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (c *csvColumn) value(val int) string {
+	entries := c.at.decode(c.l, val, &defaultOptions)
+	switch c.l.field().Kind {
+	case SigKind, FlagKind:
+		for i := range entries {
+			if entries[i].set {
+				return "1"
+			}
+		}
+		return "0"
+	}
+	var out []string
+	for i := range entries {
+		if e := &entries[i]; e.kv {
+			out = append(out, e.value)
+		} else {
+			out = append(out, e.name)
+		}
+	}
+	return strings.Join(out, "; ")
+}
+
+// ValFromCSV is like ValMap, but reads the value names from CSV data,
+// so that tables maintained outside of the Go source can be used.
+// Each record consists of two fields, a code, as accepted by
+// [strconv.ParseInt] with base 0, and the corresponding name, like
+// "0x10,IDLE". Lines starting with "#" are ignored. Malformed
+// records, codes exceeding the field, and duplicate codes result
+// in an error.
+func ValFromCSV(startBit, endBit uint, desc string, r io.Reader, dflt string) (Decoder, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+
+	max := bitMask(startBit, endBit) >> startBit
+	names := make(map[int]string)
+	for n := 1; ; n++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bindec: csv: %w", err)
+		}
+		code, err := strconv.ParseInt(strings.TrimSpace(rec[0]), 0, 0)
+		if err != nil {
+			return nil, fmt.Errorf("bindec: csv: record %d: malformed code: %q", n, rec[0])
+		}
+		b := int(code)
+		if b < 0 || b > max {
+			return nil, fmt.Errorf("bindec: csv: record %d: code out of range: %d", n, b)
+		}
+		if _, dup := names[b]; dup {
+			return nil, fmt.Errorf("bindec: csv: record %d: duplicate code: %d", n, b)
+		}
+		names[b] = rec[1]
+	}
+	return ValMap(startBit, endBit, desc, names, dflt), nil
+}
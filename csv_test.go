@@ -0,0 +1,52 @@
+package bindec_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/knieriem/bindec"
+)
+
+func TestValFromCSV(t *testing.T) {
+	const table = `# code,name
+0,IDLE
+ 0x1, RUN
+# 2 is unused
+0b11,HALT
+`
+	d, err := bindec.ValFromCSV(4, 5, "STATE", strings.NewReader(table), "?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for val, want := range []string{"STATE: IDLE", "STATE: RUN", "STATE: ?", "STATE: HALT"} {
+		got := d.Decode(nil, val<<4)
+		if !reflect.DeepEqual(got, []string{want}) {
+			t.Errorf("%#x: got %q, want %q", val<<4, got, want)
+		}
+	}
+}
+
+func TestValFromCSVErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		table string
+		err   string
+	}{
+		{"malformed code", "0,IDLE\nx1,RUN\n", `bindec: csv: record 2: malformed code: "x1"`},
+		{"empty code", ",IDLE\n", `bindec: csv: record 1: malformed code: ""`},
+		{"negative code", "-1,IDLE\n", "bindec: csv: record 1: code out of range: -1"},
+		{"code out of range", "# two bits\n3,HALT\n4,OFF\n", "bindec: csv: record 2: code out of range: 4"},
+		{"duplicate code", "1,RUN\n0x01,GO\n", "bindec: csv: record 2: duplicate code: 1"},
+		{"field count", "0,IDLE\n1,RUN,extra\n", "bindec: csv: record on line 2: wrong number of fields"},
+	} {
+		_, err := bindec.ValFromCSV(4, 5, "STATE", strings.NewReader(tc.table), "")
+		if err == nil {
+			t.Errorf("%s: no error", tc.name)
+			continue
+		}
+		if got := err.Error(); got != tc.err {
+			t.Errorf("%s: got error %q, want %q", tc.name, got, tc.err)
+		}
+	}
+}
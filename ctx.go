@@ -0,0 +1,93 @@
+package bindec
+
+// A GroupContext contains properties shared by the fields of a group,
+// so that they need not be repeated for each field.
+type GroupContext struct {
+	Unit  string  // unit of measurement, like "mV"
+	Scale float64 // factor converting raw values into the unit
+}
+
+type withCtx struct {
+	ctx GroupContext
+	d   Decoder
+}
+
+// GroupCtx is like Group, but additionally supplies ctx to the
+// fields of d, which may be consulted by fields defined using IntCtx
+// or FuncCtx. Within nested groups, fields of ctx that are not
+// set are inherited from the enclosing context.
+func GroupCtx(name string, ctx GroupContext, d Decoder) Decoder {
+	return Group(name, &withCtx{ctx, d})
+}
+
+func (c *withCtx) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, c, val)
+}
+
+func (c *withCtx) decodeEntries(e []entry, val int, o *Options) []entry {
+	co := *o
+	if c.ctx.Unit != "" {
+		co.Context.Unit = c.ctx.Unit
+	}
+	if c.ctx.Scale != 0 {
+		co.Context.Scale = c.ctx.Scale
+	}
+	return decodeEntries(c.d, e, val, &co)
+}
+
+func (c *withCtx) walk(fn walkFunc, at walkPos) {
+	walk(c.d, fn, at)
+}
+
+type intCtx struct {
+	pos    uint
+	mask   int
+	desc   string
+	format string
+	f      func(v int, ctx GroupContext) string
+}
+
+// IntCtx defines an integer Decoder that formats the value between
+// startBit and, including, endBit according to the context supplied
+// by GroupCtx: If the context's Scale is not zero, the value is
+// multiplied by it, and the resulting float64 is formatted using
+// [fmt.Sprintf](format, ...); otherwise the integer value is used.
+// If the context specifies a unit, it is appended, separated by a space.
+func IntCtx(startBit, endBit uint, desc, format string) Decoder {
+	return &intCtx{pos: startBit, mask: bitMask(startBit, endBit), desc: desc, format: format}
+}
+
+// FuncCtx is like Func, but f additionally receives the context
+// supplied by GroupCtx; the unit is not appended automatically.
+func FuncCtx(startBit, endBit uint, desc string, f func(v int, ctx GroupContext) string) Decoder {
+	return &intCtx{pos: startBit, mask: bitMask(startBit, endBit), desc: desc, f: f}
+}
+
+func (v *intCtx) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *intCtx) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := val & v.mask >> v.pos
+	if v.desc == "" {
+		return e
+	}
+	ctx := o.Context
+	var s string
+	switch {
+	case v.f != nil:
+		s = v.f(b, ctx)
+	case ctx.Scale != 0:
+		s = o.sprintf(v.format, float64(b)*ctx.Scale)
+	default:
+		s = o.sprintf(v.format, b)
+	}
+	if v.f == nil && ctx.Unit != "" {
+		s += " " + ctx.Unit
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: IntKind, raw: b})
+}
+
+func (v *intCtx) field() Field {
+	return Field{Name: v.desc, Kind: IntKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
@@ -0,0 +1,86 @@
+package bindec
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BitDiagram returns a diagram of the layout of the fields of d within
+// a value of the specified width, like
+//
+//	[15........8][7....4][3]      [2...0]
+//	COUNT        MODE    OVERTEMP ?
+//
+// consisting of two lines, showing the bit range of each field, most
+// significant bits first, and the field names beneath. Uncovered bits
+// are labelled "?", bits occupied by more than one field "!". If width
+// is zero, the width declared using WithWidth is used, or, if there
+// is no such declaration, the width resulting from MaxBit.
+func BitDiagram(d Decoder, width uint) string {
+	if width == 0 {
+		var ok bool
+		if width, ok = Width(d); !ok {
+			width = MaxBit(d) + 1
+		}
+	}
+	const (
+		uncovered = -1
+		overlap   = -2
+	)
+	fields := Fields(d)
+	owner := make([]int, width)
+	for i := range owner {
+		owner[i] = uncovered
+	}
+	for i := range fields {
+		m := fields[i].Mask()
+		for b := uint(0); b < width; b++ {
+			if m&(1<<b) == 0 {
+				continue
+			}
+			if owner[b] == uncovered {
+				owner[b] = i
+			} else {
+				owner[b] = overlap
+			}
+		}
+	}
+
+	var top, bottom strings.Builder
+	for hi := int(width) - 1; hi >= 0; {
+		lo := hi
+		for lo > 0 && owner[lo-1] == owner[hi] {
+			lo--
+		}
+		cell := "[" + strconv.Itoa(hi) + "]"
+		if n := hi - lo + 1; n > 2 {
+			cell = "[" + strconv.Itoa(hi) + strings.Repeat(".", n) + strconv.Itoa(lo) + "]"
+		} else if n == 2 {
+			cell = "[" + strconv.Itoa(hi) + ".." + strconv.Itoa(lo) + "]"
+		}
+		var label string
+		switch o := owner[hi]; o {
+		case uncovered:
+			label = "?"
+		case overlap:
+			label = "!"
+		default:
+			f := &fields[o]
+			label = f.Name
+			if label == "" {
+				label = f.Kind.String()
+			}
+			if len(f.Groups) != 0 {
+				label = strings.Join(f.Groups, ".") + "." + label
+			}
+		}
+		w := len(cell)
+		if len(label)+1 > w {
+			w = len(label) + 1
+		}
+		top.WriteString(cell + strings.Repeat(" ", w-len(cell)))
+		bottom.WriteString(label + strings.Repeat(" ", w-len(label)))
+		hi = lo - 1
+	}
+	return strings.TrimRight(top.String(), " ") + "\n" + strings.TrimRight(bottom.String(), " ") + "\n"
+}
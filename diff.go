@@ -0,0 +1,95 @@
+package bindec
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Diff returns a line for each field of Decoder d whose raw value
+// differs between old and new, like "TEMP_STAT.TEMP: 34.4 °C → 34.5 °C",
+// with field names qualified by the names of the enclosing groups.
+// The state of Sig and Flag fields is shown as "set" or "clear".
+// Fields are compared whether or not they are subject to conditions,
+// like those of Optional. See Options.Tolerance for suppressing
+// insignificant changes of numeric fields. For counter fields
+// defined by IntCounter, the difference, considering wrap-around,
+// is appended, like "COUNT: 250 → 4 (+10)".
+func Diff(d Decoder, old, new int) []string {
+	return defaultOptions.Diff(d, old, new)
+}
+
+// Diff is like the function Diff, applying the options.
+func (o *Options) Diff(d Decoder, old, new int) []string {
+	var list []string
+	walk(d, func(l leaf, at walkPos) {
+		f := l.field()
+		a, b := at.extract(l, old), at.extract(l, new)
+		if a == b {
+			return
+		}
+		name := f.Name
+		if len(at.groups) != 0 {
+			name = strings.Join(at.groups, ".") + "." + name
+		}
+		switch f.Kind {
+		case SigKind, FlagKind:
+			list = append(list, name+": "+sigState(a)+" → "+sigState(b))
+			return
+		case IntKind:
+			delta := b - a
+			counter := false
+			if v, ok := l.(*intval); ok && v.counter {
+				// (new - old) mod 2^width
+				delta &= f.Mask() >> f.StartBit
+				counter = true
+			}
+			if t, ok := o.tolerance(name, f.Name); ok && delta <= t && -delta <= t {
+				return
+			}
+			if counter {
+				list = append(list, name+": "+o.leafText(l, at, old)+" → "+o.leafText(l, at, new)+" (+"+strconv.Itoa(delta)+")")
+				return
+			}
+		}
+		list = append(list, name+": "+o.leafText(l, at, old)+" → "+o.leafText(l, at, new))
+	}, walkPos{})
+	return list
+}
+
+// tolerance looks up the tolerance of a field, trying
+// the qualified name first, then the plain name.
+func (o *Options) tolerance(qualified, name string) (int, bool) {
+	if t, ok := o.Tolerance[qualified]; ok {
+		return t, true
+	}
+	t, ok := o.Tolerance[name]
+	return t, ok
+}
+
+// leafText returns the formatted value of leaf l at position at
+// decoding val; "-" if it doesn't produce any output.
+func (o *Options) leafText(l leaf, at walkPos, val int) string {
+	entries := at.decode(l, val, o)
+	if len(o.Redact) != 0 {
+		o.redact(entries)
+	}
+	var out []string
+	for _, e := range entries {
+		if e.kv {
+			out = append(out, e.value)
+		} else {
+			out = append(out, e.text(o))
+		}
+	}
+	if out == nil {
+		return "-"
+	}
+	return strings.Join(out, "; ")
+}
+
+func sigState(raw int) string {
+	if raw != 0 {
+		return "set"
+	}
+	return "clear"
+}
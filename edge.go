@@ -0,0 +1,91 @@
+package bindec
+
+import "strings"
+
+// An Edge selects the transitions of signals reported by an EdgeDecoder.
+type Edge int
+
+const (
+	Rising  Edge = 1 << iota // transitions from 0 to 1
+	Falling                  // transitions from 1 to 0
+	Both    = Rising | Falling
+)
+
+// An EdgeDecoder reports transitions of the Sig and Flag fields of a
+// Decoder since the previous call, which is useful for edge detection
+// in polling loops. An EdgeDecoder is stateful; it must not be used
+// by several goroutines concurrently.
+type EdgeDecoder struct {
+	edge  Edge
+	sigs  []edgeSig
+	prev  int
+	valid bool
+}
+
+type edgeSig struct {
+	name      string
+	mask      int
+	l         leaf
+	activeLow bool // inverted by ActiveLow
+}
+
+// NewEdgeDecoder returns an EdgeDecoder for the signals of d,
+// reporting transitions as selected by edge.
+func NewEdgeDecoder(d Decoder, edge Edge) *EdgeDecoder {
+	x := &EdgeDecoder{edge: edge}
+	walk(d, func(l leaf, at walkPos) {
+		f := absField(l, at)
+		if f.Kind != SigKind && f.Kind != FlagKind {
+			return
+		}
+		name := f.Name
+		if len(f.Groups) != 0 {
+			name = strings.Join(f.Groups, ".") + "." + name
+		}
+		x.sigs = append(x.sigs, edgeSig{name, f.Mask(), l, at.activeLow})
+	}, walkPos{})
+	return x
+}
+
+// Decode emits a line like "OVERTEMP: rising" for each signal whose bit
+// changed since the previous call, if the direction of the transition
+// is selected. The first call after creation, or after Reset,
+// only records the state of the signals.
+func (x *EdgeDecoder) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, x, val)
+}
+
+func (x *EdgeDecoder) decodeEntries(e []entry, val int, o *Options) []entry {
+	prev, valid := x.prev, x.valid
+	x.prev, x.valid = val, true
+	if !valid {
+		return e
+	}
+	for _, s := range x.sigs {
+		inv := s.activeLow != o.activeLow
+		was, is := prev&s.mask != 0 != inv, val&s.mask != 0 != inv
+		var str string
+		switch {
+		case !was && is && x.edge&Rising != 0:
+			str = "rising"
+		case was && !is && x.edge&Falling != 0:
+			str = "falling"
+		default:
+			continue
+		}
+		raw, set := 0, is
+		if is {
+			raw = 1
+		}
+		if sig, ok := s.l.(*signal); ok && sig.negate {
+			set = !set
+		}
+		e = append(e, entry{name: s.name, value: str, kv: true, kind: s.l.field().Kind, raw: raw, set: set, src: s.l})
+	}
+	return e
+}
+
+// Reset forgets the state recorded by the previous call of Decode.
+func (x *EdgeDecoder) Reset() {
+	x.prev, x.valid = 0, false
+}
@@ -0,0 +1,26 @@
+//go:build go1.21
+// +build go1.21
+
+package bindec_test
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/knieriem/bindec"
+)
+
+func ExampleLogValue() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("sample", slog.Any("reg", bindec.LogValue(tempStatReg, 0x1759)))
+
+	// Output:
+	// level=INFO msg=sample reg.TEMP_STAT.TEMP_READY=true reg.TEMP_STAT.TEMP="34.4 °C"
+}
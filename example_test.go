@@ -1,7 +1,15 @@
 package bindec_test
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/knieriem/bindec"
 )
@@ -40,3 +48,1635 @@ func printTempStat(regVal uint16) {
 	}
 	fmt.Println()
 }
+
+func ExampleGroupAggregate() {
+	errCounters := bindec.GroupAggregate("ERRORS", func(counts []int) string {
+		total := 0
+		for _, n := range counts {
+			total += n
+		}
+		return fmt.Sprintf("(total: %d)", total)
+	}, bindec.DecoderList{
+		bindec.Int(0, 3, "CRC", "%d"),
+		bindec.Int(4, 7, "FRAMING", "%d"),
+		bindec.Sig(8, "OVERRUN"),
+	})
+
+	for _, f := range errCounters.Decode(nil, 0x152) {
+		fmt.Println(f)
+	}
+
+	// Output:
+	// ERRORS (total: 7)
+	//	CRC: 2
+	//	FRAMING: 5
+	//	OVERRUN
+}
+
+func ExampleEncodeJSONL() {
+	vals := make(chan int)
+	go func() {
+		vals <- 0x1a53
+		vals <- 0x1758
+		close(vals)
+	}()
+	bindec.EncodeJSONL(os.Stdout, tempStatReg, vals)
+
+	// Output:
+	// {"TEMP_STAT":{"TEMP_READY":true,"OVERTEMP":true,"TEMP":"73.9 °C"}}
+	// {"TEMP_STAT":{"TEMP":"34.4 °C"}}
+}
+
+func ExampleFprintVerbose() {
+	bindec.FprintVerbose(os.Stdout, tempStatReg, 0x1a53, 16)
+
+	// Output:
+	// 0x1A53 = 0001 1010 0101 0011
+	// TEMP_STAT
+	//	TEMP_READY
+	//	OVERTEMP
+	//	TEMP: 73.9 °C
+}
+
+func ExampleExplain() {
+	for _, s := range bindec.Explain(tempStatReg, 0x1759) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// TEMP_STAT.TEMP_READY (bit 0): set
+	// TEMP_STAT.OVERTEMP (bit 1): clear
+	// TEMP_STAT.TEMP (bits 4-13): raw=0x175 → 34.4 °C
+}
+
+func ExampleFprintWithHeader() {
+	bindec.FprintWithHeader(os.Stdout, "REG", tempStatReg, 0x1a53, 16)
+
+	// Output:
+	// REG 0x1A53:
+	//	TEMP_STAT
+	//		TEMP_READY
+	//		OVERTEMP
+	//		TEMP: 73.9 °C
+}
+
+func ExampleCSVWriter() {
+	cw := bindec.NewCSVWriter(os.Stdout, tempStatReg)
+	cw.Write(0x1a53)
+	cw.Write(0x1758)
+	cw.Flush()
+
+	// Output:
+	// TEMP_STAT.TEMP_READY,TEMP_STAT.OVERTEMP,TEMP_STAT.TEMP
+	// 1,1,73.9 °C
+	// 0,0,34.4 °C
+}
+
+func ExampleDiff() {
+	o := bindec.Options{Tolerance: map[string]int{"TEMP": 1}}
+	for _, s := range o.Diff(tempStatReg, 0x1a53, 0x1761) {
+		fmt.Println(s)
+	}
+	fmt.Println()
+	for _, s := range o.Diff(tempStatReg, 0x1753, 0x1761) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// TEMP_STAT.OVERTEMP: set → clear
+	// TEMP_STAT.TEMP: 73.9 °C → 35.2 °C
+	//
+	// TEMP_STAT.OVERTEMP: set → clear
+}
+
+func ExampleDecodePartition() {
+	set, clear, other := bindec.DecodePartition(tempStatReg, 0x1759)
+	fmt.Println("set:", set)
+	fmt.Println("clear:", clear)
+	fmt.Println("other:", other)
+
+	// Output:
+	// set: [TEMP_STAT.TEMP_READY]
+	// clear: [TEMP_STAT.OVERTEMP]
+	// other: [TEMP_STAT.TEMP: 34.4 °C]
+}
+
+func ExampleDecodeCanonical() {
+	for _, s := range bindec.DecodeCanonical(tempStatReg, 0x1759) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// TEMP_STAT.TEMP_READY=1
+	// TEMP_STAT.OVERTEMP=0
+	// TEMP_STAT.TEMP=373
+}
+
+func ExampleOptions_timestamp() {
+	o := bindec.Options{
+		TimestampLayout: "15:04:05.000",
+		Clock: func() time.Time {
+			return time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+		},
+	}
+	for _, s := range o.Decode(nil, tempStatReg, 0x1759) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// 12:30:00.000 TEMP_STAT
+	//	TEMP_READY
+	//	TEMP: 34.4 °C
+}
+
+func ExampleDecodeGoLiteral() {
+	fmt.Println(bindec.DecodeGoLiteral(tempStatReg, 0x1759))
+
+	// Output:
+	// []string{
+	//	"TEMP_STAT",
+	//	"\tTEMP_READY",
+	//	"\tTEMP: 34.4 °C",
+	// }
+}
+
+// chanStat is the layout of the status of a channel,
+// which is shared by both channels of chanReg.
+var chanStat = bindec.DecoderList{
+	bindec.Sig(0, "RDY"),
+	bindec.Sig(1, "ERR"),
+}
+
+var chanReg = bindec.DecoderList{
+	bindec.Group("CH0", chanStat),
+	bindec.Group("CH1", bindec.Shift(8, chanStat)),
+}
+
+func ExampleWindow() {
+	for _, s := range bindec.Window(0, 7, chanReg).Decode(nil, 0x301) {
+		fmt.Println(s)
+	}
+	fmt.Println()
+	lanes := bindec.Lanes(8, 2, "LANE%d", chanStat)
+	for _, s := range bindec.Window(8, 15, lanes).Decode(nil, 0x301) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// CH0
+	//	RDY
+	//
+	// LANE1
+	//	RDY
+	//	ERR
+}
+
+func ExampleGated() {
+	for _, s := range bindec.Gated(chanReg, 0x102).Decode(nil, 0x303) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// CH0
+	//	ERR
+	// CH1
+	//	RDY
+}
+
+func ExampleDecodeKnown() {
+	for _, s := range bindec.DecodeKnown(chanReg, 0x101, 0xff) {
+		fmt.Println(s)
+	}
+	fmt.Println()
+	o := bindec.Options{ShowUnread: true}
+	for _, s := range o.DecodeKnown(nil, chanReg, 0x101, 0xff) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// CH0
+	//	RDY
+	//
+	// CH0
+	//	RDY
+	// CH1
+	//	RDY: unknown
+	//	ERR: unknown
+}
+
+func ExampleOptions_showBits() {
+	o := bindec.Options{ShowBits: true}
+	for _, s := range o.Decode(nil, chanReg, 0x301) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// CH0
+	//	[bit 0] RDY
+	// CH1
+	//	[bit 8] RDY
+	//	[bit 9] ERR
+}
+
+func ExampleWithBitOrder() {
+	d := bindec.WithBitOrder(bindec.MSBFirst, 8, bindec.DecoderList{
+		bindec.Sig(0, "A"),
+		bindec.Int(4, 7, "N", "%d"),
+	})
+	for _, s := range d.Decode(nil, 0x81) {
+		fmt.Println(s)
+	}
+	_, ok := bindec.ExtractInt(d, "A", 0x81)
+	fmt.Println(ok)
+
+	// Output:
+	// A
+	// N: 8
+	// false
+}
+
+func ExampleActiveLow() {
+	d := bindec.ActiveLow(bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.Flag(1, "RUN"),
+		bindec.Pair(2, 3, "UP", "DOWN"),
+	})
+	fmt.Println(d.Decode(nil, 0x4))
+	set, clear, _ := bindec.DecodePartition(d, 0x4)
+	fmt.Println(set, clear)
+	for _, s := range bindec.Explain(d, 0x4) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [EN RUN DOWN]
+	// [EN RUN DOWN] [UP]
+	// EN (bit 0): set
+	// RUN (bit 1): set → RUN
+	// UP (bit 2): clear
+	// DOWN (bit 3): set
+}
+
+func ExampleThreshold() {
+	faults := bindec.Threshold(0, 7, "FAULTS", 2)
+	fmt.Println(faults.Decode(nil, 0x13))
+	fmt.Println(bindec.Threshold(0, 7, "", 2).Decode(nil, 0x13))
+	faults.OrEqual = true
+	faults.Marker = "REACHED"
+	fmt.Println(faults.Decode(nil, 0x11))
+
+	// Output:
+	// [FAULTS: 3 (threshold 2 EXCEEDED)]
+	// [3 (threshold 2 EXCEEDED)]
+	// [FAULTS: 2 (threshold 2 REACHED)]
+}
+
+func ExampleCRC() {
+	// CRC-4 (x⁴+x+1) in bits 8-11, covering bits 0-7
+	d := bindec.CRC(8, 11, 0xff, 0x3, "CRC")
+	fmt.Println(d.Decode(nil, 0xba5))
+	fmt.Println(d.Decode(nil, 0xba4))
+	fmt.Println(bindec.CRC(8, 11, 0xff, 0x3, "").Decode(nil, 0xba5))
+
+	// Output:
+	// [CRC: OK]
+	// [CRC: BAD (got 0xb, want 0x8)]
+	// [OK]
+}
+
+func ExampleOptions_runFormat() {
+	d := bindec.RepeatVal(0, 2, 4, "CH%d", []string{"OFF", "ON"}, "?")
+	o := bindec.Options{RunFormat: "%s-%s", ShowBits: true}
+	for _, s := range o.Decode(nil, d, 0x40) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [5:0] CH0-CH2: OFF
+	// [7:6] CH3: ON
+}
+
+func ExampleReservedOne() {
+	d := bindec.Group("CTRL", bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.Shift(4, bindec.ReservedOne(0, 1, "RSVD")),
+	})
+	for _, s := range d.Decode(nil, 0x21) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// CTRL
+	//	EN
+	//	RSVD: reserved bits 4-5 expected 1: 0x2
+}
+
+func ExampleFromReset() {
+	ctrl := bindec.Group("CTRL", bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.Val(1, 2, "MODE", []string{"off", "slow", "fast"}, ""),
+		bindec.Int(4, 7, "DIV", "%d"),
+		bindec.Sig(8, "OVERTEMP"),
+	})
+	d := bindec.DecoderList{
+		bindec.FromReset(0x132, ctrl),
+		bindec.FromReset(0x132, bindec.Group("STAT", bindec.Sig(12, "BUSY"))),
+	}
+	for _, s := range d.Decode(nil, 0x035) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// CTRL
+	//	EN (reset: -)
+	//	MODE: fast (reset: MODE: slow)
+	//	!OVERTEMP (reset: OVERTEMP)
+}
+
+func ExampleOptions_sortBySeverity() {
+	d := bindec.DecoderList{
+		bindec.Group("STAT", bindec.DecoderList{
+			bindec.Sig(0, "READY"),
+			bindec.SigSev(1, "LOW_BATT", bindec.SevWarning),
+		}),
+		bindec.Group("ERR", bindec.DecoderList{
+			bindec.SigSev(4, "TIMEOUT", bindec.SevWarning),
+			bindec.SigSev(5, "FAULT", bindec.SevError),
+		}),
+		bindec.Sig(8, "IDLE"),
+	}
+	o := bindec.Options{SortBySeverity: true}
+	for _, s := range o.Decode(nil, d, 0x133) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// ERR
+	//	FAULT
+	//	TIMEOUT
+	// STAT
+	//	LOW_BATT
+	//	READY
+	// IDLE
+}
+
+func ExampleDecodeStrict() {
+	d := bindec.Lanes(4, 2, "L%d", bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.ReservedOne(2, 3, "RSVD"),
+	})
+	_, err := bindec.DecodeStrict(d, 0x41)
+	fmt.Println(err)
+
+	o := bindec.Options{FailFast: true}
+	lines, err := o.DecodeStrict(d, 0x41)
+	for _, s := range lines {
+		fmt.Println(s)
+	}
+	fmt.Println(err)
+
+	// Output:
+	// bindec: L0.RSVD (bits 2-3): reserved bits 2-3 expected 1: 0x0; L1.RSVD (bits 6-7): reserved bits 6-7 expected 1: 0x1
+	// L0
+	//	EN
+	//	RSVD: reserved bits 2-3 expected 1: 0x0
+	// bindec: L0.RSVD (bits 2-3): reserved bits 2-3 expected 1: 0x0
+}
+
+func ExampleEdgeDecoder() {
+	x := bindec.NewEdgeDecoder(tempStatReg, bindec.Both)
+	for _, val := range []int{0x1758, 0x1759, 0x1a53, 0x1758} {
+		fmt.Println(x.Decode(nil, val))
+	}
+	x.Reset()
+	fmt.Println(x.Decode(nil, 0x1a53))
+
+	rising := bindec.NewEdgeDecoder(tempStatReg, bindec.Rising)
+	rising.Decode(nil, 0x1759)
+	fmt.Println(rising.Decode(nil, 0x1a52))
+
+	// Output:
+	// []
+	// [TEMP_STAT.TEMP_READY: rising]
+	// [TEMP_STAT.OVERTEMP: rising]
+	// [TEMP_STAT.TEMP_READY: falling TEMP_STAT.OVERTEMP: falling]
+	// []
+	// [TEMP_STAT.OVERTEMP: rising]
+}
+
+func ExampleFrequency() {
+	d := bindec.Frequency(0, 23, "CLK", 100)
+	for _, val := range []int{122880, 9996, 5, 0} {
+		fmt.Println(d.Decode(nil, val))
+	}
+
+	// Output:
+	// [CLK: 12.3 MHz]
+	// [CLK: 1.00 MHz]
+	// [CLK: 500 Hz]
+	// [CLK: 0 Hz]
+}
+
+func ExampleOptions_redact() {
+	key := bindec.Int(0, 7, "KEY", "%d")
+	o := bindec.Options{Redact: []string{"KEY"}}
+	fmt.Println(o.Decode(nil, key, 5))
+	fmt.Println(o.Decode(nil, bindec.Prefix("USB", ".", key), 5))
+	fmt.Println(o.Decode(nil, bindec.Complement(key), 5))
+	fmt.Println(o.Decode(nil, bindec.FromReset(3, key), 5))
+	fmt.Println(o.Diff(key, 3, 5))
+
+	// Output:
+	// [KEY: ***]
+	// [USB.KEY: ***]
+	// [~KEY: ***]
+	// [KEY: *** (reset: KEY: ***)]
+	// [KEY: *** → ***]
+}
+
+func ExampleSigVerbose() {
+	d := bindec.Prefix("USB", ".", bindec.DecoderList{
+		bindec.SigVerbose(3, "STALL", []string{"endpoint halted", "clear via CLR_STALL"}),
+		bindec.Sig(4, "SUSPEND"),
+	})
+	o := bindec.Options{Verbose: true, ShowBits: true}
+	for _, s := range o.Decode(nil, d, 0x18) {
+		fmt.Println(s)
+	}
+	fmt.Println(o.Decode(nil, bindec.Gated(d, 0x10), 0x18))
+
+	// Output:
+	// [bit 3] USB.STALL
+	//	endpoint halted
+	//	clear via CLR_STALL
+	// [bit 4] USB.SUSPEND
+	// [[bit 4] USB.SUSPEND]
+}
+
+func ExampleCapture() {
+	cnt := -1
+	d := bindec.DecoderList{
+		bindec.Sig(0, "RDY"),
+		bindec.Capture("CNT", 4, 11, &cnt),
+	}
+	fmt.Println(d.Decode(nil, 0x2a1), cnt)
+	fmt.Println(bindec.DecodeKnown(d, 0x0f1, 0xff), cnt)
+
+	// Output:
+	// [RDY] 42
+	// [RDY] 42
+}
+
+func ExampleValidate() {
+	mode0 := func(state int) bool { return state == 0 }
+	mode1 := func(state int) bool { return state == 1 }
+	d := bindec.DecoderList{
+		bindec.WhenState(mode0, bindec.Int(0, 3, "A", "%d")),
+		bindec.WhenState(mode1, bindec.Int(0, 3, "B", "%d")),
+		bindec.Sig(4, "EN"),
+		bindec.OptionalVal(4, bindec.Int(4, 7, "LEN", "%d")),
+	}
+	fmt.Println(bindec.Validate(d))
+	fmt.Println(bindec.Validate(bindec.WithWidth(6, d)))
+
+	// Output:
+	// bindec: LEN (bits 4-7) overlaps EN (bit 4)
+	// bindec: LEN (bits 4-7) overlaps EN (bit 4); LEN (bits 4-7) exceeds width 6
+}
+
+func ExampleRegisterMap() {
+	m := bindec.NewRegisterMap()
+	m.Add(0x10, "CTRL", bindec.Sig(0, "EN"))
+	m.Add(0x14, "TEMP_STAT", tempStatReg)
+	m.Add(0x10, "CTRL", bindec.WithWidth(2, bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.Sig(1, "RST"),
+	}))
+
+	lines, _ := m.Decode(0x10, 0x3)
+	for _, s := range lines {
+		fmt.Println(s)
+	}
+	_, err := m.Decode(0x18, 0)
+	fmt.Println(err)
+	fmt.Println(m.Validate())
+
+	// Output:
+	// CTRL
+	//	EN
+	//	RST
+	// bindec: no register at address 0x18
+	// bindec: TEMP_STAT (0x14): bits not covered: 0xc
+}
+
+func ExampleDecoderFromStruct() {
+	type ctrl struct {
+		Enable bool `bindec:"bit=0,name=EN,kind=sig"`
+		Mode   int  `bindec:"bits=1-2,name=MODE,kind=val,names=off|slow|fast"`
+		Div    int  `bindec:"bits=4-7,name=DIV,format=%#x"`
+		Status struct {
+			Ready bool `bindec:"bit=0,name=RDY,kind=sig"`
+			Err   bool `bindec:"bit=1,name=ERR"`
+		} `bindec:"name=STAT,shift=8"`
+		Note string
+	}
+
+	d, err := bindec.DecoderFromStruct(&ctrl{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, s := range d.Decode(nil, 0x1a5) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// EN
+	// MODE: fast
+	// DIV: 0xa
+	// STAT
+	//	RDY
+	//	!ERR
+}
+
+func ExampleSpecLoader_Load() {
+	const spec = `[
+		{"kind": "sig", "name": "EN", "bit": 0},
+		{"kind": "crc", "name": "CHK", "start": 4, "end": 7}
+	]`
+
+	_, err := bindec.LoadSpec(strings.NewReader(spec))
+	fmt.Println(err)
+
+	l := &bindec.SpecLoader{
+		UnknownKind: func(name string, start, end uint) bindec.Decoder {
+			return bindec.Int(start, end, name, "%#x")
+		},
+		Logf: func(format string, args ...interface{}) {
+			fmt.Printf("warning: "+format+"\n", args...)
+		},
+	}
+	d, err := l.Load(strings.NewReader(spec))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, s := range d.Decode(nil, 0xc1) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// bindec: spec: field "CHK": unknown kind: "crc"
+	// warning: bindec: spec: field "CHK": unknown kind "crc", using substitute
+	// EN
+	// CHK: 0xc
+}
+
+func ExampleLoadSpec() {
+	d, err := bindec.LoadSpec(strings.NewReader(`[
+		{"kind": "val", "name": "MODE", "start": 0, "end": 1, "names": ["off", "slow", "fast"]},
+		{"kind": "group", "name": "CH1", "shift": 8, "fields": [
+			{"kind": "sig", "name": "RDY", "bit": 0},
+			{"kind": "int", "name": "CNT", "start": 4, "end": 7}
+		]}
+	]`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, s := range d.Decode(nil, 0x5102) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// MODE: fast
+	// CH1
+	//	RDY
+	//	CNT: 5
+}
+
+func ExampleNewStreamDecoder() {
+	sample := bindec.DecoderList{
+		bindec.Sig(0, "RDY"),
+		bindec.Sig(1, "ERR"),
+		bindec.Int(4, 11, "CNT", "%d"),
+	}
+	capture := bytes.NewReader([]byte{0x21, 0x03, 0x52, 0x00, 0x01})
+
+	s, err := bindec.NewStreamDecoder(sample, 16, binary.LittleEndian)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for {
+		lines, err := s.Next(capture)
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		fmt.Println(strings.Join(lines, ", "))
+	}
+
+	// Output:
+	// RDY, CNT: 50
+	// ERR, CNT: 5
+	// unexpected EOF
+}
+
+func ExampleDecodeHexString() {
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		lines, err := bindec.DecodeHexString(tempStatReg, "0x1A 53", order)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(order)
+		for _, s := range lines {
+			fmt.Println(s)
+		}
+	}
+
+	// Output:
+	// BigEndian
+	// TEMP_STAT
+	//	TEMP_READY
+	//	OVERTEMP
+	//	TEMP: 73.9 °C
+	// LittleEndian
+	// TEMP_STAT
+	//	OVERTEMP
+	//	TEMP: -21.7 °C
+}
+
+// thousands is a NumberFormat grouping the digits of
+// non-negative integers. A golang.org/x/text/message.Printer
+// p would be adapted likewise, by a method returning
+// p.Sprintf(format, a...).
+type thousands string
+
+func (sep thousands) Sprintf(format string, a ...interface{}) string {
+	s := fmt.Sprintf(format, a...)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + string(sep) + s[i:]
+	}
+	return s
+}
+
+func ExampleOptions_numberFormat() {
+	d := bindec.Int(0, 23, "COUNT", "%d")
+	o := &bindec.Options{NumberFormat: thousands(".")}
+	fmt.Println(o.Decode(nil, d, 1234567))
+
+	// Output:
+	// [COUNT: 1.234.567]
+}
+
+func ExampleFields() {
+	for _, f := range bindec.Fields(chanReg) {
+		fmt.Printf("%s.%s %v %d-%d mask %#x\n", f.Groups[0], f.Name, f.Kind, f.StartBit, f.EndBit, f.Mask())
+	}
+
+	// Output:
+	// CH0.RDY sig 0-0 mask 0x1
+	// CH0.ERR sig 1-1 mask 0x2
+	// CH1.RDY sig 8-8 mask 0x100
+	// CH1.ERR sig 9-9 mask 0x200
+}
+
+func ExampleAssemble() {
+	d := bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.Flag(1, "!BUSY"),
+		bindec.Val(4, 5, "MODE", []string{"off", "slow", "fast"}, ""),
+		bindec.Int(8, 15, "DIV", "%d"),
+	}
+	val, err := bindec.Assemble(d, map[string]string{
+		"EN":   "true",
+		"BUSY": "true",
+		"MODE": "fast",
+		"DIV":  "0x12",
+	})
+	fmt.Printf("%#x %v\n", val, err)
+	fmt.Println(d.Decode(nil, val))
+
+	_, err = bindec.Assemble(d, map[string]string{"DIV": "256"})
+	fmt.Println(err)
+	_, err = bindec.Assemble(d, map[string]string{"SPEED": "1"})
+	fmt.Println(err)
+
+	// Output:
+	// 0x1221 <nil>
+	// [EN BUSY MODE: fast DIV: 18]
+	// bindec: field DIV: value out of range: 256
+	// bindec: unknown field: "SPEED"
+}
+
+// fieldNames is an Observer collecting the names of decoded fields.
+type fieldNames []string
+
+func (f *fieldNames) Field(name string, dur time.Duration) {
+	*f = append(*f, name)
+}
+
+func ExampleObserver() {
+	var names fieldNames
+	o := &bindec.Options{Observer: &names}
+	for _, s := range o.Decode(nil, tempStatReg, 0x1a53) {
+		fmt.Println(s)
+	}
+	fmt.Println(names)
+
+	// Output:
+	// TEMP_STAT
+	//	TEMP_READY
+	//	OVERTEMP
+	//	TEMP: 73.9 °C
+	// [TEMP_READY OVERTEMP TEMP]
+}
+
+func ExampleOptions_keyValueSep() {
+	o := &bindec.Options{KeyValueSep: " = "}
+	fmt.Println(o.Decode(nil, bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.Val(4, 5, "MODE", []string{"off", "slow", "fast"}, ""),
+		bindec.Int(8, 15, "DIV", "%d"),
+	}, 0x1221))
+
+	// Output:
+	// [EN MODE = fast DIV = 18]
+}
+
+func ExampleIndexed() {
+	d := bindec.Indexed(4, 5, 0, 1, "SRC", [][]string{
+		{"ADC0", "ADC1"},
+		{"TIMER", "EXT", "SW"},
+	})
+	for _, val := range []int{0x01, 0x12, 0x13, 0x20} {
+		fmt.Println(d.Decode(nil, val))
+	}
+
+	// Output:
+	// [SRC: ADC1]
+	// [SRC: SW]
+	// [SRC: 3]
+	// [SRC: 0]
+}
+
+func ExampleWithWidth() {
+	d := bindec.WithWidth(16, tempStatReg)
+	fmt.Println(bindec.Width(d))
+	fmt.Println(bindec.Width(tempStatReg))
+
+	o := &bindec.Options{WarnWidth: true}
+	for _, s := range o.Decode(nil, d, 0x31759) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// 16 true
+	// 0 false
+	// TEMP_STAT
+	//	TEMP_READY
+	//	TEMP: 34.4 °C
+	// WARNING: bits set beyond width 16: 0x30000
+}
+
+func ExampleValBits() {
+	d := bindec.ValBits(2, 4, "MODE", []string{"IDLE", "SLOW", "FAST"}, "undefined")
+	fmt.Println(d.Decode(nil, 0x08))
+	fmt.Println(d.Decode(nil, 0x1c))
+
+	// Output:
+	// [MODE: FAST (0b010)]
+	// [MODE: undefined (0b111)]
+}
+
+func ExampleDecodeFunc() {
+	n := 0
+	bindec.DecodeFunc(chanReg, 0x0301, func(line string) {
+		n++
+		fmt.Printf("%d: %q\n", n, line)
+	})
+
+	// Output:
+	// 1: "CH0"
+	// 2: "\tRDY"
+	// 3: "CH1"
+	// 4: "\tRDY"
+	// 5: "\tERR"
+}
+
+func ExampleExclusive() {
+	d := bindec.Exclusive("DIR", bindec.Sig(0, "TX"), bindec.Sig(1, "RX"))
+	fmt.Println(d.Decode(nil, 0x1))
+	fmt.Println(d.Decode(nil, 0x3))
+
+	// Output:
+	// [TX]
+	// [TX RX WARNING: DIR: mutually exclusive flags set: TX, RX]
+}
+
+func ExampleValConst() {
+	d := bindec.ValConst(0, 2, "MODE", []string{"Off", "Slow", "Fast"}, "Mode")
+	fmt.Println(d.Decode(nil, 2))
+	fmt.Println(d.Decode(nil, 5))
+
+	// Output:
+	// [MODE: ModeFast]
+	// [MODE: Mode(5)]
+}
+
+func ExampleIntWidth() {
+	d := bindec.IntWidth(0, 11, "COUNT", 5)
+	for _, val := range []int{7, 1234, 42} {
+		fmt.Printf("%q\n", d.Decode(nil, val))
+	}
+
+	// Output:
+	// ["COUNT:     7"]
+	// ["COUNT:  1234"]
+	// ["COUNT:    42"]
+}
+
+func ExampleWithState() {
+	const spiMode = 1
+	isSPI := func(state int) bool { return state == spiMode }
+	d := bindec.DecoderList{
+		bindec.WhenState(isSPI, bindec.Int(0, 3, "CLKDIV", "%d")),
+		bindec.WhenState(func(state int) bool { return !isSPI(state) }, bindec.Int(0, 3, "ADDR", "%#x")),
+	}
+	fmt.Println(d.Decode(nil, 0xa))
+	fmt.Println(bindec.WithState(spiMode, d).Decode(nil, 0xa))
+
+	o := &bindec.Options{State: spiMode}
+	fmt.Println(o.Decode(nil, d, 0xa))
+
+	// Output:
+	// [ADDR: 0xa]
+	// [CLKDIV: 10]
+	// [CLKDIV: 10]
+}
+
+func ExamplePrefix() {
+	d := bindec.Prefix("USB", ".", bindec.DecoderList{
+		bindec.Flag(0, "CONFIGURED"),
+		bindec.Val(4, 5, "SPEED", []string{"low", "full", "high"}, ""),
+		bindec.Prefix("EP0", "_", bindec.Sig(8, "STALL")),
+	})
+	for _, s := range d.Decode(nil, 0x120) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// !USB.CONFIGURED
+	// USB.SPEED: high
+	// USB.EP0_STALL
+}
+
+func ExampleOneHot() {
+	d := bindec.OneHot(0, 3, "IRQ", []string{"UART", "SPI", "I2C"})
+	for _, val := range []int{0x2, 0x8, 0x0, 0x5} {
+		fmt.Println(d.Decode(nil, val))
+	}
+
+	// Output:
+	// [IRQ: SPI]
+	// [IRQ: bit3]
+	// [IRQ: WARNING: no bit set]
+	// [IRQ: WARNING: multiple bits set: 0x5]
+}
+
+func ExampleDecodeSev() {
+	d := bindec.Group("STATUS", bindec.DecoderList{
+		bindec.Sig(0, "RDY"),
+		bindec.SigSev(1, "LOWBAT", bindec.SevWarning),
+		bindec.SigSev(2, "FAULT", bindec.SevError),
+	})
+	for _, f := range bindec.DecodeSev(d, 0x7) {
+		fmt.Printf("%-7v %q\n", f.Severity, f.Text)
+	}
+
+	// Output:
+	// info    "STATUS"
+	// info    "\tRDY"
+	// warning "\tLOWBAT"
+	// error   "\tFAULT"
+}
+
+func ExampleLimit() {
+	d := bindec.Limit(3, bindec.DecoderList{
+		bindec.Sig(0, "A"),
+		bindec.Sig(1, "B"),
+		bindec.Sig(2, "C"),
+		bindec.Sig(3, "D"),
+		bindec.Sig(4, "E"),
+	})
+	fmt.Println(d.Decode(nil, 0x1f))
+	fmt.Println(d.Decode(nil, 0x13))
+
+	// Output:
+	// [A B C ... (+2 more)]
+	// [A B E]
+}
+
+func ExampleCompose() {
+	parts := []bindec.BitRange{{Start: 12, End: 15}, {Start: 0, End: 3}}
+	u := bindec.Compose(parts, false, "OFFSET", "%#x")
+	s := bindec.Compose(parts, true, "OFFSET", "%d")
+	fmt.Println(u.Decode(nil, 0xa005))
+	fmt.Println(s.Decode(nil, 0xa005))
+	fmt.Println(s.Decode(nil, 0x3005))
+
+	// Output:
+	// [OFFSET: 0xa5]
+	// [OFFSET: -91]
+	// [OFFSET: 53]
+}
+
+func ExampleTruthTable() {
+	rows, err := bindec.TruthTable(bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.Flag(1, "!BUSY"),
+	}, 2)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, r := range rows {
+		fmt.Printf("%02b %v\n", r.Val, r.Lines)
+	}
+	_, err = bindec.TruthTable(chanReg, 32)
+	fmt.Println(err)
+
+	// Output:
+	// 00 [BUSY]
+	// 01 [EN BUSY]
+	// 10 [!BUSY]
+	// 11 [EN !BUSY]
+	// bindec: truth table width too large: 32 > 20
+}
+
+func ExampleCoverage() {
+	d := bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.Int(4, 7, "DIV", "%d"),
+		bindec.Reserved(8, 11),
+	}
+	covered, uncovered := bindec.Coverage(d, 16)
+	fmt.Printf("%#04x %#04x\n", covered, uncovered)
+	covered, uncovered = bindec.Coverage(d, 0)
+	fmt.Printf("%#04x %#04x\n", covered, uncovered)
+
+	// Output:
+	// 0x0ff1 0xf00e
+	// 0x0ff1 0x000e
+}
+
+func ExampleValUnitFor() {
+	d := bindec.ValUnitFor(12, 13, []string{"mV", "mA"}, 0, 11, "MEAS", []func(int) string{
+		func(v int) string { return strconv.Itoa(v * 2) },
+		func(v int) string { return fmt.Sprintf("%.1f", float64(v)/10) },
+	})
+	for _, val := range []int{0x0271, 0x1271, 0x2271} {
+		fmt.Println(d.Decode(nil, val))
+	}
+
+	// Output:
+	// [MEAS: 1250 mV]
+	// [MEAS: 62.5 mA]
+	// [MEAS: 625]
+}
+
+func ExampleOptions_emptyFormat() {
+	o := &bindec.Options{EmptyFormat: "%#06x: no fields set"}
+	fmt.Println(o.Decode(nil, chanStat, 0x1))
+	fmt.Println(o.Decode(nil, chanStat, 0x0))
+
+	// Output:
+	// [RDY]
+	// [0x000000: no fields set]
+}
+
+func ExampleOptional() {
+	d := bindec.DecoderList{
+		bindec.Int(0, 3, "LEN", "%d"),
+		bindec.Optional(func(val int) bool { return val&0xf >= 2 }, bindec.Int(8, 15, "OPT", "%#x")),
+		bindec.OptionalVal(4, bindec.Int(16, 23, "EXT", "%#x")),
+	}
+	fmt.Println(d.Decode(nil, 0x5a4201))
+	fmt.Println(d.Decode(nil, 0x5a4212))
+
+	// Output:
+	// [LEN: 1]
+	// [LEN: 2 OPT: 0x42 EXT: 0x5a]
+}
+
+func ExampleDecodeNode() {
+	var print func(n *bindec.Node, indent string)
+	print = func(n *bindec.Node, indent string) {
+		for _, c := range n.Children {
+			fmt.Printf("%s%s (%v) = %q\n", indent, c.Name, c.Kind, c.Value)
+			print(c, indent+"  ")
+		}
+	}
+	print(bindec.DecodeNode(tempStatReg, 0x1759), "")
+
+	// Output:
+	// TEMP_STAT (group) = ""
+	//   TEMP_READY (sig) = "true"
+	//   TEMP (int) = "34.4 °C"
+}
+
+func ExamplePipe() {
+	// The byte at bits 8-15 is an embedded status word,
+	// whose layout depends on its most significant bit.
+	short := bindec.DecoderList{bindec.Sig(7, "EXT"), bindec.Int(0, 6, "CODE", "%d")}
+	long := bindec.DecoderList{bindec.Sig(7, "EXT"), bindec.Int(4, 6, "CLASS", "%d"), bindec.Int(0, 3, "CODE", "%d")}
+	d := bindec.DecoderList{
+		bindec.Sig(0, "VALID"),
+		bindec.Pipe(bindec.Int(8, 15, "STATUS", "%d"), func(status int) bindec.Decoder {
+			if status&0x80 != 0 {
+				return long
+			}
+			return short
+		}),
+	}
+	fmt.Println(d.Decode(nil, 0x2501))
+	fmt.Println(d.Decode(nil, 0xa501))
+
+	// Output:
+	// [VALID CODE: 37]
+	// [VALID EXT CLASS: 2 CODE: 5]
+}
+
+func ExampleValRanges() {
+	d := bindec.ValRanges(0, 7, "LEVEL", []bindec.ValRange{
+		{Lo: 0, Hi: 9, Name: "low"},
+		{Lo: 10, Hi: 199, Name: "normal"},
+		{Lo: 200, Hi: 250, Name: "high"},
+	})
+	for _, val := range []int{3, 120, 200, 255} {
+		fmt.Println(d.Decode(nil, val))
+	}
+
+	fmt.Println(bindec.Validate(bindec.ValRanges(0, 7, "LEVEL", []bindec.ValRange{
+		{Lo: 0, Hi: 10, Name: "low"},
+		{Lo: 10, Hi: 199, Name: "normal"},
+	})))
+
+	// Output:
+	// [LEVEL: low]
+	// [LEVEL: normal]
+	// [LEVEL: high]
+	// [LEVEL: 255]
+	// bindec: LEVEL (bits 0-7): range normal (10-199) overlaps low (0-10)
+}
+
+func ExampleVersioned() {
+	rev := func(val int) int { return val >> 12 }
+	d := bindec.Versioned(rev, map[int]bindec.Decoder{
+		1: bindec.Int(0, 7, "GAIN", "%d"),
+		2: bindec.DecoderList{bindec.Int(0, 5, "GAIN", "%d"), bindec.Sig(6, "AUTO")},
+	}, bindec.Int(0, 11, "RAW", "%#x"))
+	for _, val := range []int{0x1047, 0x2047, 0x3047} {
+		fmt.Println(d.Decode(nil, val))
+	}
+
+	// Output:
+	// [GAIN: 71]
+	// [GAIN: 7 AUTO]
+	// [RAW: 0x47]
+}
+
+func ExampleOptions_showUnnamed() {
+	d := bindec.Val(0, 1, "MODE", []string{"off", "", "fast"}, "")
+	fmt.Println(d.Decode(nil, 1))
+	o := &bindec.Options{ShowUnnamed: true}
+	fmt.Println(o.Decode(nil, d, 1))
+
+	// Output:
+	// []
+	// [MODE: 1 (unnamed)]
+}
+
+func ExamplePinConfig() {
+	d := bindec.PinConfig(0, 4, 4, "P%d")
+	for _, s := range d.Decode(nil, 0x53) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// P0: output=1
+	// P1: output=0
+	// P2: input=1
+	// P3: input=0
+}
+
+func ExampleWithRef() {
+	d := bindec.WithRef(bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.WithRef(bindec.Int(4, 7, "DIV", "%d"), "manual §7.3.2"),
+	}, "manual §7.3")
+	for _, f := range bindec.Fields(d) {
+		fmt.Printf("%s: %s\n", f.Name, f.Ref)
+	}
+	fmt.Println(d.Decode(nil, 0x31))
+
+	// Output:
+	// EN: manual §7.3
+	// DIV: manual §7.3.2
+	// [EN DIV: 3]
+}
+
+func ExampleBCD() {
+	d := bindec.DecoderList{
+		bindec.BCD(8, 15, "YEAR"),
+		bindec.BCDRange(0, 6, "SEC", 0, 59),
+	}
+	fmt.Println(d.Decode(nil, 0x2437))
+	fmt.Println(d.Decode(nil, 0x2475))
+	fmt.Println(d.Decode(nil, 0x2a00))
+
+	// Output:
+	// [YEAR: 24 SEC: 37]
+	// [YEAR: 24 SEC: 75 (INVALID)]
+	// [YEAR: 0x2a (INVALID) SEC: 0]
+}
+
+func ExampleSource() {
+	d := bindec.DecoderList{
+		bindec.Source("STAT", tempStatReg),
+		bindec.Source("CTRL", bindec.Shift(16, bindec.Sig(0, "EN"))),
+	}
+	for _, s := range d.Decode(nil, 0x11759) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// [STAT] TEMP_STAT
+	// [STAT] 	TEMP_READY
+	// [STAT] 	TEMP: 34.4 °C
+	// [CTRL] EN
+}
+
+func ExampleReport() {
+	entries := []bindec.ReportEntry{
+		{Label: "STATUS (0x14)", Decoder: tempStatReg, Value: 0x1759},
+		{Label: "CHANNELS (0x18)", Decoder: chanReg, Value: 0},
+		{Label: "CTRL (0x10)", Decoder: bindec.Sig(0, "EN"), Value: 1},
+	}
+	o := &bindec.Options{SkipEmpty: true}
+	fmt.Print(o.Report(entries))
+
+	// Output:
+	// STATUS (0x14)
+	//	TEMP_STAT
+	//		TEMP_READY
+	//		TEMP: 34.4 °C
+	//
+	// CTRL (0x10)
+	//	EN
+}
+
+// emphasis is a Styler emphasizing fields of warning
+// and error severity, like a terminal would using
+// ANSI escape sequences.
+type emphasis struct{}
+
+func (emphasis) Style(name string, sev bindec.Severity) (prefix, suffix string) {
+	switch sev {
+	case bindec.SevError:
+		return "**", "**"
+	case bindec.SevWarning:
+		return "_", "_"
+	}
+	return "", ""
+}
+
+func ExampleStyler() {
+	d := bindec.DecoderList{
+		bindec.Sig(0, "RDY"),
+		bindec.SigSev(1, "LOWBAT", bindec.SevWarning),
+		bindec.SigSev(2, "FAULT", bindec.SevError),
+	}
+	o := &bindec.Options{Styler: emphasis{}}
+	fmt.Println(o.Decode(nil, d, 0x7))
+
+	// Output:
+	// [RDY _LOWBAT_ **FAULT**]
+}
+
+func ExampleBitReverse() {
+	d := bindec.BitReverse(4, 7, "ADDR", bindec.Int(0, 3, "ADDR", "%d"))
+	fmt.Println(d.Decode(nil, 0x10))
+	fmt.Println(d.Decode(nil, 0xc0))
+
+	// Output:
+	// [ADDR: 8]
+	// [ADDR: 3]
+}
+
+func ExampleOnlyWhen() {
+	// Report the channel status only if the error bit is set.
+	d := bindec.OnlyWhen(bindec.Match(0x2, 0x2), chanStat)
+	fmt.Println(d.Decode(nil, 0x1))
+	fmt.Println(d.Decode(nil, 0x3))
+
+	// Output:
+	// []
+	// [RDY ERR]
+}
+
+func ExampleValSet() {
+	d := bindec.ValSet(0, 3, "CLKDIV", []int{1, 2, 4, 8}, "%d")
+	fmt.Println(d.Decode(nil, 4))
+	fmt.Println(d.Decode(nil, 5))
+
+	// Output:
+	// [CLKDIV: 4]
+	// [CLKDIV: 5 (INVALID)]
+}
+
+func ExampleMaxBit() {
+	fmt.Println(bindec.MaxBit(tempStatReg))
+	fmt.Println(bindec.MaxBit(chanReg))
+	fmt.Println(bindec.MaxBit(bindec.DecoderList{}))
+
+	// Output:
+	// 13
+	// 9
+	// 0
+}
+
+func ExamplePair() {
+	d := bindec.Pair(3, 4, "UP", "DOWN")
+	for _, val := range []int{0x08, 0x10, 0x18, 0x00} {
+		fmt.Printf("%q\n", d.Decode(nil, val))
+	}
+
+	// Output:
+	// ["UP"]
+	// ["DOWN"]
+	// ["UP" "DOWN" "WARNING: inconsistent state: UP (bit 3) and DOWN (bit 4) both set"]
+	// ["WARNING: inconsistent state: neither UP (bit 3) nor DOWN (bit 4) set"]
+}
+
+func ExampleRankCandidates() {
+	layouts := map[string]bindec.Decoder{
+		"v1": bindec.DecoderList{bindec.Int(0, 7, "LEN", "%d"), bindec.ReservedOne(8, 15, "")},
+		"v2": bindec.DecoderList{bindec.Int(0, 7, "LEN", "%d"), bindec.Int(8, 11, "CH", "%d")},
+		"v3": bindec.DecoderList{bindec.Int(0, 11, "LEN", "%d"), bindec.Sig(12, "LAST")},
+	}
+	const val = 0x0312
+
+	decode := bindec.Candidates(layouts)
+	m := decode(val)
+	for _, name := range bindec.RankCandidates(layouts, val, 16) {
+		fmt.Println(name, m[name])
+	}
+
+	// Output:
+	// v2 [LEN: 18 CH: 3]
+	// v3 [LEN: 786]
+	// v1 [LEN: 18 reserved bits 8-15 expected 1: 0x3]
+}
+
+func ExampleUnixTime() {
+	built := bindec.UnixTime(0, 30, "BUILT", time.Second)
+	fmt.Println(built.Decode(nil, 0x65fd6a80))
+
+	since := bindec.UnixTimeSigned(0, 15, "SINCE", time.Hour)
+	fmt.Println(since.Decode(nil, 0xffff))
+
+	// Output:
+	// [BUILT: 2024-03-22T11:24:48Z]
+	// [SINCE: 1969-12-31T23:00:00Z]
+}
+
+func ExampleCheckNames() {
+	fmt.Println(bindec.CheckNames(tempStatReg))
+	fmt.Println(bindec.CheckNames(chanReg))
+	fmt.Println(bindec.CheckNames(bindec.DecoderList{
+		bindec.Group("RDY", bindec.Sig(0, "EN")),
+		bindec.Prefix("CH1", ".", chanStat),
+	}))
+
+	// Output:
+	// <nil>
+	// bindec: duplicate name "RDY": CH0.RDY (bit 0), CH1.RDY (bit 8); duplicate name "ERR": CH0.ERR (bit 1), CH1.ERR (bit 9)
+	// bindec: duplicate name "RDY": RDY (group), CH1.RDY (bit 0)
+}
+
+func ExampleActivityMap() {
+	m := bindec.ActivityMap(tempStatReg, []int{0x1759, 0x1759, 0x1761, 0x1a53})
+	for _, name := range []string{"TEMP_STAT.TEMP_READY", "TEMP_STAT.OVERTEMP", "TEMP_STAT.TEMP"} {
+		fmt.Println(name, m[name])
+	}
+
+	// Output:
+	// TEMP_STAT.TEMP_READY 0
+	// TEMP_STAT.OVERTEMP 1
+	// TEMP_STAT.TEMP 2
+}
+
+func ExamplePointer() {
+	descriptors := map[int]string{0x00: "RX ring", 0x40: "TX ring"}
+	d := bindec.DecoderList{
+		bindec.Pointer(0, 7, "NEXT", func(offset int) string {
+			if s, ok := descriptors[offset]; ok {
+				return fmt.Sprintf("%s @%#x", s, offset)
+			}
+			return fmt.Sprintf("invalid @%#x", offset)
+		}),
+		bindec.Pointer(8, 15, "PREV", nil),
+	}
+	fmt.Println(d.Decode(nil, 0x2040))
+	fmt.Println(d.Decode(nil, 0x0010))
+
+	// Output:
+	// [NEXT: TX ring @0x40 PREV: 0x20]
+	// [NEXT: invalid @0x10 PREV: 0x0]
+}
+
+func ExampleComboVal() {
+	d := bindec.ComboVal([]uint{5, 0}, "MODE", map[int]string{
+		0: "idle",
+		1: "rx",
+		2: "tx",
+	}, "duplex")
+	for _, val := range []int{0x00, 0x01, 0x20, 0x21} {
+		fmt.Println(d.Decode(nil, val))
+	}
+
+	// Output:
+	// [MODE: idle]
+	// [MODE: rx]
+	// [MODE: tx]
+	// [MODE: duplex]
+}
+
+func ExampleBitDiagram() {
+	fmt.Print(bindec.BitDiagram(bindec.DecoderList{
+		bindec.Int(8, 15, "COUNT", "%d"),
+		bindec.Val(4, 7, "MODE", nil, ""),
+		bindec.Sig(3, "OVERTEMP"),
+	}, 16))
+	fmt.Print(bindec.BitDiagram(bindec.DecoderList{
+		bindec.Int(0, 3, "LEN", "%d"),
+		bindec.Sig(2, "EN"),
+	}, 0))
+
+	// Output:
+	// [15........8][7....4][3]      [2...0]
+	// COUNT        MODE    OVERTEMP ?
+	// [3] [2][1..0]
+	// LEN !  LEN
+}
+
+func ExampleIntValidated() {
+	d := bindec.IntValidated(0, 3, "DIV", "%d", func(v int) error {
+		if v%2 != 0 {
+			return errors.New("divider must be even")
+		}
+		return nil
+	})
+	fmt.Println(d.Decode(nil, 4))
+	fmt.Println(d.Decode(nil, 3))
+
+	// Output:
+	// [DIV: 4]
+	// [DIV: 3 (divider must be even)]
+}
+
+func ExampleFlagsField() {
+	d := bindec.FlagsField(8, 15, "CAPS", []string{"DMA", "IRQ", "", "64BIT"})
+	fmt.Println(d.Decode(nil, 0x2b00))
+	fmt.Println(d.Decode(nil, 0x00ff))
+
+	// Output:
+	// [CAPS: DMA|IRQ|64BIT|bit5]
+	// []
+}
+
+func ExampleValAlias() {
+	d := bindec.ValAlias(0, 2, "RATE", map[int]string{0: "off", 1: "1 Hz", 2: "10 Hz"}, map[int]int{3: 2, 7: 0}, "reserved")
+	for _, val := range []int{1, 3, 7, 5} {
+		fmt.Println(d.Decode(nil, val))
+	}
+	fmt.Println(bindec.Assemble(d, map[string]string{"RATE": "10 Hz"}))
+
+	// Output:
+	// [RATE: 1 Hz]
+	// [RATE: 10 Hz]
+	// [RATE: off]
+	// [RATE: reserved]
+	// 2 <nil>
+}
+
+func ExampleValDuration() {
+	d := bindec.ValDuration(0, 1, "TIMEOUT", []time.Duration{0, 100 * time.Millisecond, 2 * time.Second}, "reserved")
+	for _, val := range []int{0, 1, 2, 3} {
+		fmt.Println(d.Decode(nil, val))
+	}
+
+	// Output:
+	// [TIMEOUT: disabled]
+	// [TIMEOUT: 100ms]
+	// [TIMEOUT: 2s]
+	// [TIMEOUT: reserved]
+}
+
+func ExampleRegisterMap_Validate() {
+	m := bindec.NewRegisterMap()
+	m.Add(0x10, "CTRL", bindec.WithWidth(8, bindec.DecoderList{
+		bindec.Sig(0, "EN"),
+		bindec.Int(0, 3, "DIV", "%d"),
+		bindec.Reserved(4, 7),
+	}))
+	m.Add(0x14, "CH", chanReg)
+	m.Add(0x18, "TEMP", bindec.WithWidth(16, tempStatReg))
+
+	err := m.Validate()
+	if verr, ok := err.(*bindec.ValidationError); ok {
+		for _, p := range verr.Problems {
+			fmt.Println(p)
+		}
+	}
+
+	// Output:
+	// CTRL (0x10): DIV (bits 0-3) overlaps EN (bit 0)
+	// CH (0x14): duplicate name "RDY": CH0.RDY (bit 0), CH1.RDY (bit 8)
+	// CH (0x14): duplicate name "ERR": CH0.ERR (bit 1), CH1.ERR (bit 9)
+	// CH (0x14): bits not covered: 0xfc
+	// TEMP (0x18): bits not covered: 0xc00c
+}
+
+func ExampleGroupCtx() {
+	d := bindec.GroupCtx("ADC", bindec.GroupContext{Unit: "mV", Scale: 0.5}, bindec.DecoderList{
+		bindec.IntCtx(0, 7, "CH0", "%.1f"),
+		bindec.GroupCtx("AUX", bindec.GroupContext{Scale: 2}, bindec.IntCtx(8, 15, "CH1", "%.0f")),
+		bindec.FuncCtx(16, 19, "GAIN", func(v int, ctx bindec.GroupContext) string {
+			return fmt.Sprintf("%d (%s per step: %g)", v, ctx.Unit, ctx.Scale)
+		}),
+	})
+	for _, s := range d.Decode(nil, 0x30a15) {
+		fmt.Println(s)
+	}
+
+	// Output:
+	// ADC
+	//	CH0: 10.5 mV
+	//	AUX
+	//		CH1: 20 mV
+	//	GAIN: 3 (mV per step: 0.5)
+}
+
+func ExampleDecodeBindings() {
+	for _, b := range bindec.DecodeBindings(tempStatReg, 0x1759) {
+		fmt.Printf("%-10s %-4v raw=%-3d writable=%-5v %q %q\n", b.Name, b.Kind, b.Raw, b.Writable, b.Text, b.Value)
+	}
+
+	// Output:
+	// TEMP_READY sig  raw=1   writable=true  "TEMP_READY" "true"
+	// OVERTEMP   sig  raw=0   writable=true  "-" "false"
+	// TEMP       int  raw=373 writable=true  "34.4 °C" "373"
+}
+
+// linkSpeed is an enum type, as it might be
+// accompanied by a String method generated by stringer.
+type linkSpeed int
+
+const (
+	speed10M linkSpeed = iota
+	speed100M
+	speed1G
+)
+
+func (s linkSpeed) String() string {
+	return [...]string{"Speed10M", "Speed100M", "Speed1G"}[s]
+}
+
+func ExampleValStringerChecked() {
+	d := bindec.ValStringerChecked(0, 2, "SPEED", func(v int) (fmt.Stringer, bool) {
+		return linkSpeed(v), v <= int(speed1G)
+	})
+	fmt.Println(d.Decode(nil, 2))
+	fmt.Println(d.Decode(nil, 7))
+
+	// Output:
+	// [SPEED: Speed1G]
+	// [SPEED: 7 (invalid)]
+}
+
+func ExampleIntCounter() {
+	d := bindec.DecoderList{
+		bindec.IntCounter(0, 7, "FRAMES", "%d"),
+		bindec.Int(8, 15, "LEVEL", "%d"),
+	}
+	fmt.Println(bindec.Diff(d, 0x05fa, 0x0704))
+
+	// Output:
+	// [FRAMES: 250 → 4 (+10) LEVEL: 5 → 7]
+}
+
+func ExampleComplement() {
+	d := bindec.DecoderList{
+		chanStat,
+		bindec.Complement(chanStat),
+	}
+	fmt.Println(d.Decode(nil, 0x1))
+	fmt.Println(len(bindec.Fields(d)))
+
+	level := bindec.WithWidth(8, bindec.Int(0, 7, "LEVEL", "%#x"))
+	fmt.Println(bindec.Complement(level).Decode(nil, 0x0f))
+
+	// Output:
+	// [RDY ~ERR]
+	// 2
+	// [~LEVEL: 0xf0]
+}
+
+func ExampleCBitmask() {
+	d := bindec.CBitmask(0, 7, "OPEN", map[int]string{
+		0x1: "O_RDONLY",
+		0x2: "O_WRONLY",
+		0x4: "O_CREAT",
+	})
+	for _, val := range []int{0x5, 0x32, 0x0} {
+		fmt.Println(d.Decode(nil, val))
+	}
+
+	// Output:
+	// [OPEN: O_RDONLY | O_CREAT]
+	// [OPEN: O_WRONLY | 0x30]
+	// [OPEN: 0]
+}
+
+func ExampleFieldValues() {
+	m := bindec.FieldValues(tempStatReg, 0x1759)
+	fmt.Println(m)
+	val, err := bindec.Assemble(tempStatReg, m)
+	fmt.Printf("%#x %v\n", val, err)
+
+	// Output:
+	// map[OVERTEMP:false TEMP:373 TEMP_READY:true]
+	// 0x1751 <nil>
+}
+
+func ExampleDecodeOrdered() {
+	f := bindec.DecodeOrdered(tempStatReg, 0x1759)
+	b, err := json.Marshal(f)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(string(b))
+
+	stat, _ := f.Get("TEMP_STAT")
+	fmt.Println(stat.(*bindec.OrderedFields).Get("TEMP"))
+
+	// Output:
+	// {"TEMP_STAT":{"TEMP_READY":true,"TEMP":"34.4 °C"}}
+	// 34.4 °C true
+}
+
+func ExampleRepeat() {
+	d := bindec.Repeat(0, 4, 3, "FIFO%d", "%d")
+	fmt.Println(d.Decode(nil, 0x0a53))
+
+	// Output:
+	// [FIFO0: 3 FIFO1: 5 FIFO2: 10]
+}
+
+func ExampleValMap() {
+	d := bindec.ValMap(0, 7, "CMD", map[int]string{0x00: "NOP", 0x10: "READ", 0xff: "RESET"}, "")
+	fmt.Println(d.Decode(nil, 0x10))
+	fmt.Println(d.Decode(nil, 0x11))
+
+	csv := "# code,name\n0x00,NOP\n0x10,READ\n0xff,RESET\n"
+	d, err := bindec.ValFromCSV(0, 7, "CMD", strings.NewReader(csv), "unknown")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(d.Decode(nil, 0xff))
+	fmt.Println(d.Decode(nil, 0x11))
+
+	// Output:
+	// [CMD: READ]
+	// []
+	// [CMD: RESET]
+	// [CMD: unknown]
+}
+
+func ExampleGatedState() {
+	// The enable mask is read from a separate register.
+	d := bindec.GatedState(chanStat)
+	for _, enable := range []int{0x1, 0x3} {
+		fmt.Println(bindec.WithState(enable, d).Decode(nil, 0x3))
+	}
+
+	// Output:
+	// [RDY]
+	// [RDY ERR]
+}
@@ -0,0 +1,73 @@
+package bindec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Explain returns a description of how each field of Decoder d is
+// extracted from val, like "TEMP (bits 4-13): raw=0x1a5 → 34.4 °C",
+// showing the bit range, the raw field value in hexadecimal, and the
+// formatted output. For Sig and Flag fields the state of the bit is shown.
+// Field names are qualified by the names of the enclosing groups.
+// Fields are explained whether or not they are subject to
+// conditions, like those of Optional.
+func Explain(d Decoder, val int) []string {
+	var list []string
+	walk(d, func(l leaf, at walkPos) {
+		f := absField(l, at)
+		raw := at.extract(l, val)
+		var out []string
+		for _, e := range at.decode(l, val, &defaultOptions) {
+			if e.kv {
+				out = append(out, e.value)
+			} else {
+				out = append(out, e.text(&defaultOptions))
+			}
+		}
+		s := fieldRef(&f) + ": "
+		switch f.Kind {
+		case SigKind, FlagKind:
+			if raw != 0 {
+				s += "set"
+			} else {
+				s += "clear"
+			}
+			if f.Kind == SigKind {
+				list = append(list, s)
+				return
+			}
+		default:
+			s += fmt.Sprintf("raw=%#x", raw)
+		}
+		if len(out) != 0 {
+			s += " → " + strings.Join(out, "; ")
+		}
+		list = append(list, s)
+	}, walkPos{})
+	return list
+}
+
+// DecodeCanonical returns a presentation-independent representation
+// of val, as decoded by d, intended for comparisons against golden
+// files: For each field, in declaration order, a line like
+// "TEMP_STAT.TEMP=421" is returned, containing the raw field value
+// in decimal notation. Names are qualified by the names of the
+// enclosing groups; fields without a name are identified by their
+// kind and bit range. All fields are included, like cleared signals,
+// and those subject to conditions.
+func DecodeCanonical(d Decoder, val int) []string {
+	var list []string
+	walk(d, func(l leaf, at walkPos) {
+		f := absField(l, at)
+		name := f.Name
+		if name == "" {
+			name = fieldRef(&f)
+		} else if len(f.Groups) != 0 {
+			name = strings.Join(f.Groups, ".") + "." + name
+		}
+		list = append(list, name+"="+strconv.Itoa(at.extract(l, val)))
+	}, walkPos{})
+	return list
+}
@@ -0,0 +1,285 @@
+package bindec
+
+import "math/bits"
+
+// Kind specifies the kind of a field.
+type Kind int
+
+const (
+	TextKind     Kind = iota // other output, like warnings
+	SigKind                  // defined by Sig
+	FlagKind                 // defined by Flag
+	ValKind                  // defined by Val
+	IntKind                  // defined by Int or Func
+	ReservedKind             // defined by Reserved or ReservedOne
+	GroupKind                // group header, as defined by Group
+)
+
+var kindNames = []string{
+	TextKind:     "text",
+	SigKind:      "sig",
+	FlagKind:     "flag",
+	ValKind:      "val",
+	IntKind:      "int",
+	ReservedKind: "reserved",
+	GroupKind:    "group",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "kind?"
+}
+
+// A Field describes a single field of a value,
+// as defined by one of the leaf Decoders like Sig or Val.
+type Field struct {
+	Name     string
+	Kind     Kind
+	StartBit uint // absolute bit position, considering Shift
+	EndBit   uint
+
+	// Names contains the value names of a ValKind field.
+	Names []string
+
+	// Groups contains the names of the enclosing groups,
+	// including those defined by Prefix, starting with
+	// the outermost one.
+	Groups []string
+
+	// Ref is a reference, like a link to a section of the
+	// manual, as attached using WithRef. It doesn't affect
+	// the output of decoders, but may be used by tools
+	// generating documentation.
+	Ref string
+
+	// mask, if not zero, contains the bits of a field
+	// not occupying all bits between StartBit and EndBit.
+	mask int
+}
+
+// Mask returns the bit mask of the field.
+func (f *Field) Mask() int {
+	if f.mask != 0 {
+		return f.mask
+	}
+	return bitMask(f.StartBit, f.EndBit)
+}
+
+// A leaf is implemented by Decoders that decode a single field.
+type leaf interface {
+	Decoder
+
+	// field returns a description of the field,
+	// with bit positions relative to the leaf.
+	field() Field
+}
+
+// A walkPos describes the position of a leaf within a Decoder tree.
+type walkPos struct {
+	off    uint      // bit offset resulting from enclosing Shift decoders
+	groups []string  // names of the enclosing groups
+	owners []Decoder // the enclosing group decoders, corresponding to groups
+	ref    string    // reference attached using WithRef
+
+	activeLow bool // set within decoders wrapped by ActiveLow
+
+	// conds contains the enclosing conditional decoders,
+	// like WhenState or Optional, starting with the outermost one.
+	conds []Decoder
+}
+
+func (at walkPos) shifted(n uint) walkPos {
+	at.off += n
+	return at
+}
+
+func (at walkPos) inGroup(name string, owner Decoder) walkPos {
+	at.groups = append(at.groups[:len(at.groups):len(at.groups)], name)
+	at.owners = append(at.owners[:len(at.owners):len(at.owners)], owner)
+	return at
+}
+
+func (at walkPos) conditional(d Decoder) walkPos {
+	at.conds = append(at.conds[:len(at.conds):len(at.conds)], d)
+	return at
+}
+
+// exclusive reports whether the leaves at positions at and other
+// may not be decoded together, as they are subject to different
+// conditional decoders.
+func (at walkPos) exclusive(other walkPos) bool {
+	a, b := at.conds, other.conds
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// walkFunc is called for each leaf of a Decoder tree.
+type walkFunc func(l leaf, at walkPos)
+
+// A branch is implemented by Decoders containing sub-Decoders.
+type branch interface {
+	walk(fn walkFunc, at walkPos)
+}
+
+func walk(d Decoder, fn walkFunc, at walkPos) {
+	switch d := d.(type) {
+	case branch:
+		d.walk(fn, at)
+	case leaf:
+		fn(d, at)
+	}
+}
+
+// Fields returns a description of the fields
+// defined by a Decoder tree, in declaration order.
+func Fields(d Decoder) []Field {
+	var list []Field
+	walk(d, func(l leaf, at walkPos) {
+		list = append(list, absField(l, at))
+	}, walkPos{})
+	return list
+}
+
+func absField(l leaf, at walkPos) Field {
+	f := l.field()
+	f.StartBit += at.off
+	f.EndBit += at.off
+	f.mask <<= at.off
+	if len(at.groups) != 0 {
+		f.Groups = append([]string(nil), at.groups...)
+	}
+	if f.Ref == "" {
+		f.Ref = at.ref
+	}
+	return f
+}
+
+func (s *signal) field() Field {
+	k := SigKind
+	if s.isFlag {
+		k = FlagKind
+	}
+	return Field{Name: s.name, Kind: k, StartBit: s.pos, EndBit: s.pos}
+}
+
+func (v *value) field() Field {
+	return Field{Name: v.desc, Kind: ValKind, StartBit: v.pos, EndBit: endBit(v.mask), Names: v.names}
+}
+
+func (v *intval) field() Field {
+	return Field{Name: v.desc, Kind: IntKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+// endBit returns the position of the highest bit set in mask.
+func endBit(mask int) uint {
+	if mask == 0 {
+		return 0
+	}
+	return uint(bits.Len(uint(mask))) - 1
+}
+
+func (list DecoderList) walk(fn walkFunc, at walkPos) {
+	for _, d := range list {
+		walk(d, fn, at)
+	}
+}
+
+func (s shift) walk(fn walkFunc, at walkPos) {
+	walk(s.d, fn, at.shifted(s.pos))
+}
+
+func (g *group) walk(fn walkFunc, at walkPos) {
+	walk(g.d, fn, at.inGroup(g.name, g))
+}
+
+func (p *prefix) walk(fn walkFunc, at walkPos) {
+	walk(p.d, fn, at.inGroup(p.name, p))
+}
+
+// ExtractInt returns the raw value of the field named name within val,
+// as extracted by the leaf Decoder defining the field within the tree d,
+// considering enclosing Shift decoders. Sig and Flag fields result in 0 or 1,
+// considering inversion by ActiveLow.
+// If no such field exists, ok is false. In case several fields have
+// the same name, the first one is used.
+func ExtractInt(d Decoder, name string, val int) (v int, ok bool) {
+	walk(d, func(l leaf, at walkPos) {
+		if ok {
+			return
+		}
+		if f := l.field(); f.Name == name {
+			v, ok = at.extract(l, val), true
+		}
+	}, walkPos{})
+	return v, ok
+}
+
+// extract returns the raw field value of leaf l within val, considering
+// enclosing Shift decoders, and the inversion of signals by ActiveLow.
+func (at walkPos) extract(l leaf, val int) int {
+	v := extract(l, val>>at.off)
+	if _, ok := l.(*signal); ok && at.activeLow {
+		v ^= 1
+	}
+	return v
+}
+
+// decode decodes the field of leaf l within val, like extract,
+// considering enclosing Shift and ActiveLow decoders.
+func (at walkPos) decode(l leaf, val int, o *Options) []entry {
+	if at.activeLow != o.activeLow {
+		o1 := *o
+		o1.activeLow = at.activeLow
+		o = &o1
+	}
+	return decodeEntries(l, nil, val>>at.off, o)
+}
+
+// An extractor is implemented by leaf Decoders that
+// don't extract their field simply by masking and shifting.
+type extractor interface {
+	extract(val int) int
+}
+
+// extract returns the raw field value of leaf l within val.
+func extract(l leaf, val int) int {
+	if x, ok := l.(extractor); ok {
+		return x.extract(val)
+	}
+	f := l.field()
+	return val & f.Mask() >> f.StartBit
+}
+
+type ref struct {
+	ref string
+	d   Decoder
+}
+
+// WithRef attaches a reference, like a link to a manual section,
+// to the fields defined by d, which is reported by Fields.
+// References attached to nested decoders take precedence.
+func WithRef(d Decoder, reference string) Decoder {
+	return &ref{reference, d}
+}
+
+func (r *ref) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, r, val)
+}
+
+func (r *ref) decodeEntries(e []entry, val int, o *Options) []entry {
+	return decodeEntries(r.d, e, val, o)
+}
+
+func (r *ref) walk(fn walkFunc, at walkPos) {
+	at.ref = r.ref
+	walk(r.d, fn, at)
+}
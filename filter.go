@@ -0,0 +1,35 @@
+package bindec
+
+import "fmt"
+
+type limit struct {
+	n int
+	d Decoder
+}
+
+// Limit restricts the output of Decoder d to n lines. If d produces
+// more lines, the output is truncated, followed by a summary line
+// like "... (+5 more)", reporting the number of suppressed lines.
+func Limit(n int, d Decoder) Decoder {
+	if n < 0 {
+		n = 0
+	}
+	return &limit{n, d}
+}
+
+func (l *limit) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, l, val)
+}
+
+func (l *limit) decodeEntries(e []entry, val int, o *Options) []entry {
+	n := len(e)
+	e = decodeEntries(l.d, e, val, o)
+	if more := len(e) - n - l.n; more > 0 {
+		e = append(e[:n+l.n], entry{name: fmt.Sprintf("... (+%d more)", more)})
+	}
+	return e
+}
+
+func (l *limit) walk(fn walkFunc, at walkPos) {
+	walk(l.d, fn, at)
+}
@@ -0,0 +1,134 @@
+package bindec
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// GenerateGoStruct generates the Go source of a struct type named
+// typeName, having one field per named leaf of Decoder d, and a
+// method Decode(val int) that sets the fields to the values extracted
+// from val. Sig and Flag fields result in bool fields, that are true
+// if the field decodes to its name; all other fields result in int
+// fields containing the raw field value. Field names are derived from the
+// field and group names, like TempStatTemp for "TEMP_STAT.TEMP".
+// The source consists of declarations only, it doesn't contain a
+// package clause. Fields whose raw value isn't simply a contiguous
+// range of bits, like those defined by Compose, are not supported,
+// as well as fields whose Go name would clash with the Decode method.
+func GenerateGoStruct(d Decoder, typeName string) (string, error) {
+	type genField struct {
+		name   string
+		f      Field
+		isBool bool
+		negate bool
+	}
+	var fields []genField
+	seen := make(map[string]bool)
+	var err error
+	walk(d, func(l leaf, at walkPos) {
+		f := absField(l, at)
+		if err != nil || f.Name == "" || f.Name == "<reserved>" || f.Kind == ReservedKind {
+			return
+		}
+		if _, ok := l.(extractor); ok || f.mask != 0 {
+			err = fmt.Errorf("bindec: generate: %s: unsupported field", fieldRef(&f))
+			return
+		}
+		name := goName(append(append([]string(nil), f.Groups...), f.Name))
+		if name == "Decode" {
+			err = fmt.Errorf("bindec: generate: %s: Go name %s clashes with method Decode", fieldRef(&f), name)
+			return
+		}
+		if seen[name] {
+			err = fmt.Errorf("bindec: generate: %s: duplicate Go name %s", fieldRef(&f), name)
+			return
+		}
+		seen[name] = true
+		gf := genField{name: name, f: f}
+		if s, ok := l.(*signal); ok {
+			gf.isBool = true
+			gf.negate = s.negate != at.activeLow
+		}
+		fields = append(fields, gf)
+	}, walkPos{})
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "type %s struct {\n", typeName)
+	for _, gf := range fields {
+		typ := "int"
+		if gf.isBool {
+			typ = "bool"
+		}
+		fmt.Fprintf(&b, "%s %s // %s\n", gf.name, typ, fieldRef(&gf.f))
+	}
+	fmt.Fprintf(&b, "}\n\n")
+	fmt.Fprintf(&b, "// Decode sets the fields of x to the values contained in val.\n")
+	fmt.Fprintf(&b, "func (x *%s) Decode(val int) {\n", typeName)
+	for _, gf := range fields {
+		f := &gf.f
+		switch {
+		case gf.isBool && gf.negate:
+			fmt.Fprintf(&b, "x.%s = val&%#x == 0\n", gf.name, f.Mask())
+		case gf.isBool:
+			fmt.Fprintf(&b, "x.%s = val&%#x != 0\n", gf.name, f.Mask())
+		default:
+			fmt.Fprintf(&b, "x.%s = val >> %d & %#x\n", gf.name, f.StartBit, f.Mask()>>f.StartBit)
+		}
+	}
+	fmt.Fprintf(&b, "}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("bindec: generate: %w", err)
+	}
+	return string(src), nil
+}
+
+// goName converts a qualified field name into an exported Go
+// identifier, like TempStatTemp for "TEMP_STAT", "TEMP".
+func goName(parts []string) string {
+	var b strings.Builder
+	for _, p := range parts {
+		for _, w := range strings.FieldsFunc(p, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			r, n := utf8.DecodeRuneInString(w)
+			b.WriteRune(unicode.ToUpper(r))
+			b.WriteString(strings.ToLower(w[n:]))
+		}
+	}
+	s := b.String()
+	if r, _ := utf8.DecodeRuneInString(s); !unicode.IsUpper(r) {
+		// not exported, or not starting with a letter
+		s = "F" + s
+	}
+	return s
+}
+
+// DecodeGoLiteral returns the output of d decoding val as a Go slice
+// literal, with one quoted line per element, like
+//
+//	[]string{
+//		"TEMP_STAT",
+//		"\tTEMP_READY",
+//	}
+//
+// which is useful for creating test fixtures from real captures.
+func DecodeGoLiteral(d Decoder, val int) string {
+	var b strings.Builder
+	b.WriteString("[]string{\n")
+	for _, s := range d.Decode(nil, val) {
+		b.WriteString("\t" + strconv.Quote(s) + ",\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
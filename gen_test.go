@@ -0,0 +1,63 @@
+package bindec_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/knieriem/bindec"
+)
+
+func TestGenerateGoStruct(t *testing.T) {
+	d := bindec.DecoderList{
+		tempStatReg,
+		bindec.Group("ÉTAT", bindec.DecoderList{
+			bindec.Flag(14, "!überlauf"),
+			bindec.Sig(15, "日本"),
+		}),
+		bindec.Val(16, 17, "MODE", []string{"OFF", "ON"}, ""),
+		bindec.Int(18, 21, "2ND", "%d"),
+		bindec.Sig(22, "日本"),
+	}
+	src, err := bindec.GenerateGoStruct(d, "Reg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "gen.go", "package p\n\n"+src, 0)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, src)
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, src)
+	}
+	st := pkg.Scope().Lookup("Reg").Type().Underlying().(*types.Struct)
+	var names []string
+	for i := 0; i < st.NumFields(); i++ {
+		names = append(names, st.Field(i).Name())
+	}
+	want := "TempStatTempReady TempStatOvertemp TempStatTemp ÉtatÜberlauf État日本 Mode F2nd F日本"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("fields: got %q, want %q", got, want)
+	}
+}
+
+func TestGenerateGoStructErrors(t *testing.T) {
+	for _, tc := range []struct {
+		d   bindec.Decoder
+		err string
+	}{
+		{bindec.Sig(0, "DECODE"), "clashes with method Decode"},
+		{bindec.DecoderList{bindec.Sig(0, "A_B"), bindec.Sig(1, "a-b")}, "duplicate Go name AB"},
+		{bindec.ComboVal([]uint{0, 2}, "C", nil, ""), "unsupported field"},
+	} {
+		_, err := bindec.GenerateGoStruct(tc.d, "Reg")
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("got error %v, want %q", err, tc.err)
+		}
+	}
+}
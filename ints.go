@@ -0,0 +1,463 @@
+package bindec
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IntWidth defines an integer Decoder like Int, that formats
+// the value as a decimal number, right-justified to width columns,
+// so that the values of a field line up when comparing
+// several readings of the same register line by line.
+func IntWidth(startBit, endBit uint, desc string, width int) Decoder {
+	return Int(startBit, endBit, desc, "%"+strconv.Itoa(width)+"d")
+}
+
+// Pointer defines a Decoder for a field between startBit and,
+// including, endBit that contains an offset into another structure.
+// The offset is passed to resolve, which returns a description of
+// the target, like "next descriptor @0x40". If resolve is nil,
+// the offset is displayed in hexadecimal.
+func Pointer(startBit, endBit uint, desc string, resolve func(offset int) string) Decoder {
+	if resolve == nil {
+		return Int(startBit, endBit, desc, "%#x")
+	}
+	return Func(startBit, endBit, desc, resolve)
+}
+
+// A BitRange specifies the bits between Start and, including, End.
+type BitRange struct {
+	Start, End uint
+}
+
+type compose struct {
+	parts  []BitRange
+	signed bool
+	desc   string
+	format string
+	mask   int
+	width  uint
+}
+
+// Compose defines an integer Decoder for fields whose bits are not
+// contiguous. The bits of the specified parts are concatenated into one
+// value, with the first part forming the most significant bits, and the
+// last part the least significant bits. If signed is true, the value
+// is sign-extended, based on the total number of bits. The result
+// is formatted using [fmt.Sprintf].
+func Compose(parts []BitRange, signed bool, desc, format string) Decoder {
+	c := &compose{parts: parts, signed: signed, desc: desc, format: format}
+	for _, p := range parts {
+		c.mask |= bitMask(p.Start, p.End)
+		c.width += p.End - p.Start + 1
+	}
+	return c
+}
+
+func (c *compose) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, c, val)
+}
+
+func (c *compose) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := c.extract(val)
+	if c.desc == "" {
+		return e
+	}
+	return append(e, entry{name: c.desc, value: o.sprintf(c.format, b), kv: true, kind: IntKind, raw: b})
+}
+
+func (c *compose) extract(val int) int {
+	b := 0
+	for _, p := range c.parts {
+		b = b<<(p.End-p.Start+1) | val&bitMask(p.Start, p.End)>>p.Start
+	}
+	if c.signed && c.width != 0 && b&(1<<(c.width-1)) != 0 {
+		b -= 1 << c.width
+	}
+	return b
+}
+
+func (c *compose) field() Field {
+	return Field{Name: c.desc, Kind: IntKind, StartBit: uint(bits.TrailingZeros(uint(c.mask))), EndBit: endBit(c.mask), mask: c.mask}
+}
+
+type unitFor struct {
+	selPos  uint
+	selMask int
+	units   []string
+	pos     uint
+	mask    int
+	desc    string
+	conv    []func(int) string
+}
+
+// ValUnitFor defines an integer Decoder for a measurement field
+// accompanied by a unit selector field. The value between unitSelStart
+// and, including, unitSelEnd selects both the unit name from units,
+// and the function from conv that converts the value between valStart
+// and valEnd to a string. The unit name is appended to the result,
+// separated by a space. If the selector exceeds conv, the value is
+// displayed as a decimal number; if it exceeds units, no unit is shown.
+func ValUnitFor(unitSelStart, unitSelEnd uint, units []string, valStart, valEnd uint, desc string, conv []func(int) string) Decoder {
+	return &unitFor{
+		selPos:  unitSelStart,
+		selMask: bitMask(unitSelStart, unitSelEnd),
+		units:   units,
+		pos:     valStart,
+		mask:    bitMask(valStart, valEnd),
+		desc:    desc,
+		conv:    conv,
+	}
+}
+
+func (v *unitFor) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *unitFor) decodeEntries(e []entry, val int, o *Options) []entry {
+	sel := val & v.selMask >> v.selPos
+	b := val & v.mask >> v.pos
+
+	var s string
+	if sel < len(v.conv) && v.conv[sel] != nil {
+		s = v.conv[sel](b)
+	} else {
+		s = strconv.Itoa(b)
+	}
+	if sel < len(v.units) && v.units[sel] != "" {
+		s += " " + v.units[sel]
+	}
+	if v.desc == "" {
+		return e
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: IntKind, raw: b})
+}
+
+func (v *unitFor) field() Field {
+	return Field{Name: v.desc, Kind: IntKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+type bcd struct {
+	pos        uint
+	mask       int
+	desc       string
+	checkRange bool
+	min, max   int
+}
+
+// BCD defines an integer Decoder for binary-coded decimal fields, as
+// used by RTC registers. Each nibble of the value between startBit and,
+// including, endBit is interpreted as a decimal digit. If a nibble is
+// not a valid digit, the raw value is displayed in hexadecimal,
+// followed by "(INVALID)".
+func BCD(startBit, endBit uint, desc string) Decoder {
+	return &bcd{pos: startBit, mask: bitMask(startBit, endBit), desc: desc}
+}
+
+// BCDRange is like BCD, but additionally appends "(INVALID)"
+// if the decoded value is outside [min, max], to detect corrupt
+// reads of RTC registers, like seconds beyond 59.
+func BCDRange(startBit, endBit uint, desc string, min, max int) Decoder {
+	return &bcd{pos: startBit, mask: bitMask(startBit, endBit), desc: desc, checkRange: true, min: min, max: max}
+}
+
+func (v *bcd) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *bcd) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := val & v.mask >> v.pos
+
+	var s string
+	sev := SevInfo
+	n, ok := fromBCD(b)
+	switch {
+	case !ok:
+		s = fmt.Sprintf("%#x (INVALID)", b)
+		sev = SevWarning
+	case v.checkRange && (n < v.min || n > v.max):
+		s = strconv.Itoa(n) + " (INVALID)"
+		sev = SevWarning
+	default:
+		s = strconv.Itoa(n)
+	}
+	if v.desc == "" {
+		return e
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: IntKind, raw: n, sev: sev})
+}
+
+// fromBCD converts a binary-coded decimal into an integer.
+// The result is false if a nibble is not a decimal digit.
+func fromBCD(b int) (int, bool) {
+	n := 0
+	for scale := 1; b != 0; scale *= 10 {
+		d := b & 0xF
+		if d > 9 {
+			return 0, false
+		}
+		n += d * scale
+		b >>= 4
+	}
+	return n, true
+}
+
+func (v *bcd) field() Field {
+	return Field{Name: v.desc, Kind: IntKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+func (v *bcd) encode(s string) (int, error) {
+	if strings.HasPrefix(s, "0x") {
+		// raw value, as returned by valueString for invalid digits
+		b, err := strconv.ParseInt(s, 0, 0)
+		return int(b), err
+	}
+	n, err := strconv.ParseUint(s, 10, 0)
+	if err != nil {
+		return 0, err
+	}
+	b, err := strconv.ParseInt(strconv.FormatUint(n, 10), 16, 0)
+	return int(b), err
+}
+
+func (v *bcd) valueString(raw int) string {
+	if n, ok := fromBCD(raw); ok {
+		return strconv.Itoa(n)
+	}
+	return fmt.Sprintf("%#x", raw)
+}
+
+type valSet struct {
+	pos     uint
+	mask    int
+	desc    string
+	allowed []int
+	format  string
+}
+
+// ValSet defines an integer Decoder for a field that must hold one of
+// the values in allowed, like a clock divider accepting 1, 2, 4, or 8.
+// The value between startBit and, including, endBit is formatted using
+// [fmt.Sprintf]; if it is not contained in allowed, " (INVALID)"
+// is appended.
+func ValSet(startBit, endBit uint, desc string, allowed []int, format string) Decoder {
+	return &valSet{startBit, bitMask(startBit, endBit), desc, allowed, format}
+}
+
+func (v *valSet) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *valSet) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := val & v.mask >> v.pos
+	if v.desc == "" {
+		return e
+	}
+	s := o.sprintf(v.format, b)
+	sev := SevWarning
+	for _, a := range v.allowed {
+		if a == b {
+			sev = SevInfo
+			break
+		}
+	}
+	if sev != SevInfo {
+		s += " (INVALID)"
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: IntKind, raw: b, sev: sev})
+}
+
+func (v *valSet) field() Field {
+	return Field{Name: v.desc, Kind: IntKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+func (v *valSet) encode(s string) (int, error) {
+	i, err := strconv.ParseInt(s, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	return int(i), nil
+}
+
+func (v *valSet) valueString(raw int) string {
+	return strconv.Itoa(raw)
+}
+
+type intValidated struct {
+	pos      uint
+	mask     int
+	desc     string
+	format   string
+	validate func(int) error
+}
+
+// IntValidated defines an integer Decoder like Int, that additionally
+// checks the value between startBit and, including, endBit using
+// validate. If validate returns an error, its text is appended in
+// parentheses, like "DIV: 3 (divider must be even)", and the line
+// is reported as an anomaly by DecodeStrict.
+func IntValidated(startBit, endBit uint, desc, format string, validate func(int) error) Decoder {
+	return &intValidated{startBit, bitMask(startBit, endBit), desc, format, validate}
+}
+
+func (v *intValidated) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *intValidated) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := val & v.mask >> v.pos
+	if v.desc == "" {
+		return e
+	}
+	s := o.sprintf(v.format, b)
+	sev := SevInfo
+	if err := v.validate(b); err != nil {
+		s += " (" + err.Error() + ")"
+		sev = SevWarning
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: IntKind, raw: b, sev: sev})
+}
+
+func (v *intValidated) field() Field {
+	return Field{Name: v.desc, Kind: IntKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+func (v *intValidated) encode(s string) (int, error) {
+	i, err := strconv.ParseInt(s, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	return int(i), nil
+}
+
+func (v *intValidated) valueString(raw int) string {
+	return strconv.Itoa(raw)
+}
+
+type unixTime struct {
+	pos    uint
+	mask   int
+	desc   string
+	unit   time.Duration
+	signed bool
+}
+
+// UnixTime defines a Decoder for a field between startBit and,
+// including, endBit that contains a timestamp as a count of unit since
+// the Unix epoch, like time.Second or time.Millisecond. The
+// timestamp is displayed in UTC, formatted as RFC 3339.
+func UnixTime(startBit, endBit uint, desc string, unit time.Duration) Decoder {
+	return &unixTime{startBit, bitMask(startBit, endBit), desc, unit, false}
+}
+
+// UnixTimeSigned is like UnixTime, but the field is sign-extended,
+// so that times before the epoch can be represented.
+func UnixTimeSigned(startBit, endBit uint, desc string, unit time.Duration) Decoder {
+	return &unixTime{startBit, bitMask(startBit, endBit), desc, unit, true}
+}
+
+func (v *unixTime) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *unixTime) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := v.extract(val)
+	if v.desc == "" {
+		return e
+	}
+	var t time.Time
+	if v.unit%time.Second == 0 {
+		t = time.Unix(int64(b)*int64(v.unit/time.Second), 0)
+	} else {
+		t = time.Unix(0, int64(b)*int64(v.unit))
+	}
+	s := t.UTC().Format(time.RFC3339Nano)
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: IntKind, raw: b})
+}
+
+func (v *unixTime) extract(val int) int {
+	b := val & v.mask >> v.pos
+	if w := endBit(v.mask) - v.pos + 1; v.signed && b&(1<<(w-1)) != 0 {
+		b -= 1 << w
+	}
+	return b
+}
+
+func (v *unixTime) field() Field {
+	return Field{Name: v.desc, Kind: IntKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+// Frequency defines a Decoder for a field between startBit and,
+// including, endBit that contains a frequency in units of hzPerUnit.
+// The frequency is displayed with three significant digits, using
+// an SI prefix, like "12.3 MHz".
+func Frequency(startBit, endBit uint, desc string, hzPerUnit float64) Decoder {
+	return Func(startBit, endBit, desc, func(v int) string {
+		return siString(float64(v)*hzPerUnit, "Hz")
+	})
+}
+
+var siPrefixes = []string{"p", "n", "µ", "m", "", "k", "M", "G", "T", "P"}
+
+// siString formats v with three significant digits,
+// followed by an SI prefix and unit, like "12.3 MHz".
+func siString(v float64, unit string) string {
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return strconv.FormatFloat(v, 'g', -1, 64) + " " + unit
+	}
+	exp := int(math.Floor(math.Log10(math.Abs(v))))
+	// round to three significant digits first, as this may
+	// carry over into the next power of ten
+	v = math.Round(v/math.Pow10(exp-2)) * math.Pow10(exp-2)
+	exp = int(math.Floor(math.Log10(math.Abs(v))))
+
+	i := int(math.Floor(float64(exp)/3)) + 4
+	if i < 0 {
+		i = 0
+	} else if i >= len(siPrefixes) {
+		i = len(siPrefixes) - 1
+	}
+	scaled := v / math.Pow10((i-4)*3)
+	prec := 2 - (exp - (i-4)*3)
+	if prec < 0 {
+		prec = 0
+	}
+	return strconv.FormatFloat(scaled, 'f', prec, 64) + " " + siPrefixes[i] + unit
+}
+
+type capture struct {
+	pos  uint
+	mask int
+	name string
+	sink *int
+}
+
+// Capture defines a Decoder that doesn't produce any output, but stores
+// the raw value between startBit and, including, endBit into *sink
+// each time a value is decoded, so that several field values can be
+// collected during a single pass, next to the decoders producing the
+// output. As the sink is shared, a Decoder containing a Capture must
+// not be used by several goroutines concurrently. Within DecodeKnown,
+// if not all bits of the field are known, *sink is left unchanged.
+func Capture(name string, startBit, endBit uint, sink *int) Decoder {
+	return &capture{startBit, bitMask(startBit, endBit), name, sink}
+}
+
+func (c *capture) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, c, val)
+}
+
+func (c *capture) decodeEntries(e []entry, val int, o *Options) []entry {
+	*c.sink = val & c.mask >> c.pos
+	return e
+}
+
+func (c *capture) silent() {}
+
+func (c *capture) field() Field {
+	return Field{Name: c.name, Kind: IntKind, StartBit: c.pos, EndBit: endBit(c.mask)}
+}
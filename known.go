@@ -0,0 +1,39 @@
+package bindec
+
+// DecodeKnown decodes val like d.Decode would do, in case only the bits
+// in knownMask have actually been read, like after a partial register
+// read. Fields whose bits are all known are decoded as usual; fields
+// that are only partially known are displayed like "desc: unknown",
+// and fields none of whose bits are known are omitted, unless
+// Options.ShowUnread is set.
+func DecodeKnown(d Decoder, val int, knownMask int) []string {
+	return defaultOptions.DecodeKnown(nil, d, val, knownMask)
+}
+
+// DecodeKnown is like the function DecodeKnown, applying the options,
+// and appending the output to w.
+func (o *Options) DecodeKnown(w []string, d Decoder, val int, knownMask int) []string {
+	o1 := *o
+	o1.unknown = ^knownMask
+	return o1.Decode(w, d, val&knownMask)
+}
+
+// A silentLeaf is implemented by leaf Decoders that never produce
+// any output, like Capture, so that DecodeKnown doesn't display them.
+type silentLeaf interface {
+	leaf
+	silent()
+}
+
+// appendUnread appends an entry for leaf l, whose bits within the
+// top-level value, as specified by mask, are not all known.
+func appendUnread(e []entry, l leaf, mask int, o *Options) []entry {
+	if _, ok := l.(silentLeaf); ok {
+		return e
+	}
+	f := l.field()
+	if mask&^o.unknown == 0 && !o.ShowUnread || f.Name == "" {
+		return e
+	}
+	return append(e, entry{name: f.Name, value: "unknown", kv: true, kind: f.Kind, src: l, mask: mask})
+}
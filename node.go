@@ -0,0 +1,58 @@
+package bindec
+
+import "strconv"
+
+// A Node represents decoder output as a tree, which is
+// useful for rendering using templates, for example.
+type Node struct {
+	// Name is the name of the field or group.
+	Name string
+
+	// Value is the formatted value of the field; for Sig and Flag
+	// fields it is "true" or "false", depending on the state of the
+	// signal. It is empty for groups, and for other lines, like warnings.
+	Value string
+
+	// Text is the line as emitted by Decode, without indentation.
+	Text string
+
+	Kind     Kind
+	Severity Severity
+	Children []*Node
+}
+
+// DecodeNode decodes val using d and returns the output as a tree.
+// The root node has no name and holds the top-level lines as children;
+// groups result in nodes containing children.
+func DecodeNode(d Decoder, val int) *Node {
+	return defaultOptions.DecodeNode(d, val)
+}
+
+// DecodeNode is like the function DecodeNode, applying the options.
+func (o *Options) DecodeNode(d Decoder, val int) *Node {
+	root := &Node{Kind: GroupKind}
+	stack := []*Node{root}
+	for _, e := range o.decode(d, nil, val) {
+		n := &Node{
+			Name:     e.keyName(),
+			Text:     e.text(o),
+			Kind:     e.kind,
+			Severity: e.sev,
+		}
+		switch {
+		case e.isSig():
+			n.Value = strconv.FormatBool(e.set)
+		case e.kv:
+			n.Value = e.value
+		}
+		if e.depth+1 < len(stack) {
+			stack = stack[:e.depth+1]
+		}
+		parent := stack[len(stack)-1]
+		parent.Children = append(parent.Children, n)
+		if e.kind == GroupKind {
+			stack = append(stack, n)
+		}
+	}
+	return root
+}
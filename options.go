@@ -0,0 +1,281 @@
+package bindec
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Options modify the way Decoders produce their output.
+// The zero value results in the same output as calling
+// a Decoder's Decode method directly.
+type Options struct {
+	// If not nil, Observer gets notified about the time
+	// spent decoding each leaf field.
+	Observer Observer
+
+	// KeyValueSep separates the description of fields
+	// decoded by Val, Int, or Func from their value.
+	// If empty, ": " is used.
+	KeyValueSep string
+
+	// If WarnWidth is set, decoders annotated using WithWidth
+	// emit a warning if the value has bits set beyond the
+	// declared width.
+	WarnWidth bool
+
+	// State is an external state that decoders defined
+	// using WhenState may consult. See also WithState.
+	State int
+
+	// Context is the context set by GroupCtx,
+	// as consulted by IntCtx and FuncCtx.
+	Context GroupContext
+
+	// If SortBySeverity is set, output lines are sorted by the
+	// severity of the fields, errors first, then warnings, then
+	// informational lines, retaining the original order of lines
+	// of the same severity. Sorting is done within each group;
+	// a group is ranked by the highest severity of its contents.
+	SortBySeverity bool
+
+	// If EmptyFormat is not empty, and decoding a value doesn't
+	// produce any output, a single line is emitted instead, which
+	// results from formatting the value using [fmt.Sprintf] with
+	// EmptyFormat, like "%#06x: no fields set".
+	EmptyFormat string
+
+	// By default, values of Val fields mapping to an empty
+	// string within the names slice are not displayed. If
+	// ShowUnnamed is set, they are displayed like "desc: 5 (unnamed)",
+	// which helps detecting gaps in names tables.
+	ShowUnnamed bool
+
+	// If SkipEmpty is set, Report omits sections
+	// whose decoding doesn't produce any output.
+	SkipEmpty bool
+
+	// If not nil, Styler is consulted for each line produced by
+	// a leaf field, to wrap it into style markers, like ANSI escape
+	// sequences highlighting asserted error flags.
+	Styler Styler
+
+	// If RunFormat is not empty, decoders defined by Repeat
+	// or RepeatVal collapse runs of consecutive fields having
+	// the same value into one line, with the description formatted
+	// using [fmt.Sprintf](RunFormat, firstDesc, lastDesc).
+	// For instance, "%s-%s" results in lines like "CH0-CH2: 0".
+	RunFormat string
+
+	// By default, DecodeKnown omits fields none of whose bits are
+	// known. If ShowUnread is set, they are displayed like partially
+	// known fields, as "desc: unknown".
+	ShowUnread bool
+
+	// If ShowBits is set, the output of each leaf field is prefixed
+	// with the range of bits it has been extracted from, like
+	// "[13:4] TEMP: 34.4 °C", considering enclosing Shift decoders.
+	// Single-bit fields are prefixed like "[bit 1]".
+	ShowBits bool
+
+	// Tolerance contains, per name of an integer field, the amount by
+	// which its raw value may change without being reported by Diff,
+	// to suppress sensor noise, for instance. Names may be qualified
+	// by the names of the enclosing groups, like "TEMP_STAT.TEMP",
+	// which takes precedence over the plain field name.
+	Tolerance map[string]int
+
+	// If TimestampLayout is not empty, the first line of the output
+	// of each decoded value is prefixed with the current time,
+	// formatted using [time.Time.Format] with TimestampLayout,
+	// followed by a space, which helps correlating live dumps
+	// with other logs.
+	TimestampLayout string
+
+	// Clock returns the current time used for timestamps;
+	// if nil, [time.Now] is used.
+	Clock func() time.Time
+
+	// Redact lists the names of fields whose values are replaced
+	// by "***" after formatting, like keys or serial numbers, so
+	// that the output can be shared without leaking them. The fields
+	// themselves still appear, like "KEY: ***". Redaction also applies
+	// to the output of Diff, and of decoders defined by FromReset.
+	Redact []string
+
+	// If not nil, NumberFormat is used instead of [fmt.Sprintf]
+	// to format the values of numeric fields using the format
+	// strings specified for Int, IntCtx, Compose, and similar
+	// decoders, to apply localized digit grouping and decimal
+	// separators, for instance. Since the Sprintf method of a
+	// golang.org/x/text/message.Printer takes a message.Reference,
+	// a small adapter is needed to use a Printer here.
+	NumberFormat NumberFormat
+
+	// If Verbose is set, fields defined using SigVerbose
+	// emit their detailed descriptions.
+	Verbose bool
+
+	// If FailFast is set, DecodeStrict stops decoding at the first
+	// anomaly, instead of reporting all of them.
+	FailFast bool
+
+	// activeLow is set within decoders wrapped by ActiveLow.
+	activeLow bool
+
+	// off is the position, within the top-level value, of the bits
+	// currently being decoded, resulting from enclosing Shift decoders
+	// and lanes. If opaque is set, the bits being decoded don't
+	// correspond to those of the top-level value, like within Pipe.
+	off    uint
+	opaque bool
+
+	// If qualify is set, as done by DecodeStrict, groups contains
+	// the names of the enclosing groups, including those defined by
+	// Prefix, and entries stemming from leaves are annotated with a
+	// reference to their field, like "CTRL.RSVD (bits 4-7)".
+	qualify bool
+	groups  []string
+
+	// failed, if not nil, is set to true by decodeEntries as soon
+	// as an anomaly has been detected, so that further decoding
+	// is skipped. See FailFast.
+	failed *bool
+
+	// unknown contains the bits of the top-level value
+	// that haven't been read. See DecodeKnown.
+	unknown int
+}
+
+var defaultOptions Options
+
+// Decode appends the decoded representation of val,
+// as produced by d, to w, applying the options.
+func (o *Options) Decode(w []string, d Decoder, val int) []string {
+	return render(w, o.decode(d, nil, val), o)
+}
+
+// decode decodes val using d at the top level,
+// applying post-processing steps requested by the options.
+func (o *Options) decode(d Decoder, e []entry, val int) []entry {
+	n := len(e)
+	e = decodeEntries(d, e, val, o)
+	if len(o.Redact) != 0 {
+		o.redact(e[n:])
+	}
+	if o.SortBySeverity {
+		sortBySeverity(e[n:])
+	}
+	if len(e) == n && o.EmptyFormat != "" {
+		e = append(e, entry{name: fmt.Sprintf(o.EmptyFormat, val)})
+	}
+	if len(e) != n && o.TimestampLayout != "" {
+		now := time.Now
+		if o.Clock != nil {
+			now = o.Clock
+		}
+		e[n].tag = now().Format(o.TimestampLayout) + " " + e[n].tag
+	}
+	return e
+}
+
+// redact replaces the values of the entries
+// of fields listed in o.Redact.
+func (o *Options) redact(e []entry) {
+	for i := range e {
+		if c := &e[i]; c.kv && o.redacted(c) {
+			c.value = "***"
+		}
+	}
+}
+
+// redacted reports whether entry c stems from a leaf field listed
+// in o.Redact. The name of the field is used, instead of the name of
+// the entry, which may have been changed by decoders like Prefix.
+func (o *Options) redacted(c *entry) bool {
+	l, ok := c.src.(leaf)
+	if !ok {
+		return false
+	}
+	name := l.field().Name
+	for _, r := range o.Redact {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// inGroup returns a copy of o for decoding the contents of
+// the group named name, if o.qualify is set.
+func (o *Options) inGroup(name string) *Options {
+	if !o.qualify {
+		return o
+	}
+	o1 := *o
+	o1.groups = append(o.groups[:len(o.groups):len(o.groups)], name)
+	return &o1
+}
+
+// A NumberFormat formats numbers according to a format
+// string, like [fmt.Sprintf] does. See Options.NumberFormat.
+type NumberFormat interface {
+	Sprintf(format string, a ...interface{}) string
+}
+
+func (o *Options) sprintf(format string, a ...interface{}) string {
+	if o.NumberFormat != nil {
+		return o.NumberFormat.Sprintf(format, a...)
+	}
+	return fmt.Sprintf(format, a...)
+}
+
+func (o *Options) sep() string {
+	if o.KeyValueSep == "" {
+		return ": "
+	}
+	return o.KeyValueSep
+}
+
+var entryPool = sync.Pool{
+	New: func() interface{} { return new([]entry) },
+}
+
+// DecodeFunc is like Decode, but instead of appending the output
+// lines to a slice, it calls emit for each line, with indentation
+// already applied. The intermediate buffers are reused across calls,
+// so that decoding large numbers of values doesn't need per-value
+// allocations.
+func (o *Options) DecodeFunc(d Decoder, val int, emit func(line string)) {
+	buf := entryPool.Get().(*[]entry)
+	entries := o.decode(d, (*buf)[:0], val)
+	for i := range entries {
+		emit(entries[i].line(o))
+	}
+	for i := range entries {
+		entries[i] = entry{}
+	}
+	*buf = entries[:0]
+	entryPool.Put(buf)
+}
+
+// DecodeFunc calls emit for each output line of Decoder d
+// decoding val, using default Options.
+func DecodeFunc(d Decoder, val int, emit func(line string)) {
+	defaultOptions.DecodeFunc(d, val, emit)
+}
+
+// A Styler returns markers to be placed around the output
+// of a field of the specified name and severity. For Flag fields,
+// name doesn't contain the "!" prefix of cleared flags.
+type Styler interface {
+	Style(name string, sev Severity) (prefix, suffix string)
+}
+
+// An Observer can be used to profile decoding, for instance
+// to find out which Func decoders consume most of the time.
+type Observer interface {
+	// Field is called after a leaf field named name
+	// has been decoded, taking duration dur.
+	Field(name string, dur time.Duration)
+}
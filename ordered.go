@@ -0,0 +1,137 @@
+package bindec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// OrderedFields contains decoded fields in declaration order,
+// which is retained when marshaling them to JSON or YAML.
+type OrderedFields struct {
+	List []OrderedField
+}
+
+// An OrderedField maps the name of a field to its value, which is a bool
+// for Sig and Flag fields, a *OrderedFields for groups, and a string
+// containing the formatted value for other fields. Lines without a value,
+// like those produced by a Val without description, are represented by
+// their text mapping to true.
+type OrderedField struct {
+	Key   string
+	Value interface{}
+}
+
+// DecodeOrdered decodes val using d, and returns
+// the output as OrderedFields.
+func DecodeOrdered(d Decoder, val int) *OrderedFields {
+	return defaultOptions.DecodeOrdered(d, val)
+}
+
+// DecodeOrdered is like the function DecodeOrdered, applying the options.
+func (o *Options) DecodeOrdered(d Decoder, val int) *OrderedFields {
+	f, _ := orderedFields(o.decode(d, nil, val), o)
+	return f
+}
+
+// orderedFields converts entries of the same depth, and their
+// children, into OrderedFields. It returns the remaining entries.
+func orderedFields(entries []entry, o *Options) (*OrderedFields, []entry) {
+	f := new(OrderedFields)
+	depth := -1
+	for len(entries) != 0 {
+		e := &entries[0]
+		if depth == -1 {
+			depth = e.depth
+		} else if e.depth < depth {
+			break
+		}
+		entries = entries[1:]
+		var v interface{}
+		switch {
+		case e.kind == GroupKind:
+			v, entries = orderedFields(entries, o)
+		case e.isSig():
+			v = e.set
+		case e.kv:
+			v = e.value
+		default:
+			f.List = append(f.List, OrderedField{e.text(o), true})
+			continue
+		}
+		f.List = append(f.List, OrderedField{e.keyName(), v})
+	}
+	return f, entries
+}
+
+// Get returns the value of the first field named key.
+func (f *OrderedFields) Get(key string) (value interface{}, ok bool) {
+	for _, kv := range f.List {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// MarshalJSON returns a JSON object containing
+// the fields in declaration order.
+func (f *OrderedFields) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, kv := range f.List {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		v, err := json.Marshal(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// MarshalYAML implements the Marshaler interface of the
+// common YAML packages. Since plain Go maps don't retain order,
+// the fields are represented as a sequence of single-entry mappings.
+func (f *OrderedFields) MarshalYAML() (interface{}, error) {
+	seq := make([]map[string]interface{}, len(f.List))
+	for i, kv := range f.List {
+		seq[i] = map[string]interface{}{kv.Key: kv.Value}
+	}
+	return seq, nil
+}
+
+// EncodeJSONL reads values from channel vals until it is closed,
+// and writes the decoded representation of each value, as produced
+// by d, as a JSON object on a separate line to w (JSON Lines format).
+// The objects are structured like the output of DecodeOrdered.
+// If w implements a Flush method, like [bufio.Writer], it is called
+// after each line.
+func EncodeJSONL(w io.Writer, d Decoder, vals <-chan int) error {
+	flusher, _ := w.(interface{ Flush() error })
+	for val := range vals {
+		b, err := DecodeOrdered(d, val).MarshalJSON()
+		if err != nil {
+			return err
+		}
+		b = append(b, '\n')
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		if flusher != nil {
+			if err := flusher.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
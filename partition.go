@@ -0,0 +1,31 @@
+package bindec
+
+import "strings"
+
+// DecodePartition decodes val using d, and partitions the output by kind:
+// The names of the Sig and Flag fields that decode to their name,
+// considering negation, are returned in set, the names of the other
+// signals in clear. The output of all other fields, like
+// "TEMP: 34.4 °C", is returned in other. Names are qualified by the
+// names of the enclosing groups, like "TEMP_STAT.OVERTEMP".
+func DecodePartition(d Decoder, val int) (set, clear, other []string) {
+	walk(d, func(l leaf, at walkPos) {
+		f := l.field()
+		qual := ""
+		if len(at.groups) != 0 {
+			qual = strings.Join(at.groups, ".") + "."
+		}
+		if s, ok := l.(*signal); ok {
+			if (at.extract(l, val) != 0) != s.negate {
+				set = append(set, qual+f.Name)
+			} else {
+				clear = append(clear, qual+f.Name)
+			}
+			return
+		}
+		for _, e := range at.decode(l, val, &defaultOptions) {
+			other = append(other, qual+e.text(&defaultOptions))
+		}
+	}, walkPos{})
+	return set, clear, other
+}
@@ -0,0 +1,60 @@
+package bindec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FprintVerbose prints val in hexadecimal and binary notation, like
+// "0x1A53 = 0001 1010 0101 0011", followed by the decoded representation
+// of the value, as produced by d, one line each. The number of digits
+// depends on width; binary digits are grouped into nibbles.
+func FprintVerbose(w io.Writer, d Decoder, val int, width uint) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s = %s\n", hexString(val, width), binString(val, width))
+	for _, s := range d.Decode(nil, val) {
+		fmt.Fprintln(bw, s)
+	}
+	return bw.Flush()
+}
+
+// FprintWithHeader prints a header line like "REG 0x1A53:", followed
+// by the decoded representation of val, as produced by d, indented by
+// one tab character. The number of hex digits depends on width; if it
+// is zero, the width declared using WithWidth is used, or, if
+// there is no such declaration, the width resulting from MaxBit.
+func FprintWithHeader(w io.Writer, label string, d Decoder, val int, width uint) error {
+	if width == 0 {
+		var ok bool
+		if width, ok = Width(d); !ok {
+			width = MaxBit(d) + 1
+		}
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s %s:\n", label, hexString(val, width))
+	for _, s := range d.Decode(nil, val) {
+		fmt.Fprintln(bw, "\t"+s)
+	}
+	return bw.Flush()
+}
+
+// hexString formats val as a hexadecimal number, with
+// as many digits as needed to represent width bits.
+func hexString(val int, width uint) string {
+	return fmt.Sprintf("0x%0*X", int(width+3)/4, uint(val)&(1<<width-1))
+}
+
+// binString formats the lowest width bits of val as a
+// binary number, with digits grouped into nibbles.
+func binString(val int, width uint) string {
+	var b strings.Builder
+	for i := int(width) - 1; i >= 0; i-- {
+		b.WriteByte('0' + byte(val>>uint(i)&1))
+		if i != 0 && i%4 == 0 {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package bindec
+
+import "fmt"
+
+// A RegisterMap models the register file of a device,
+// as a collection of Decoders addressed by register offset.
+type RegisterMap struct {
+	regs   []register
+	byAddr map[uint]int
+}
+
+type register struct {
+	addr uint
+	name string
+	d    Decoder
+}
+
+// NewRegisterMap returns an empty RegisterMap.
+func NewRegisterMap() *RegisterMap {
+	return &RegisterMap{byAddr: make(map[uint]int)}
+}
+
+// Add defines a register at address addr, its values
+// being decoded by d. In case a register has already been
+// defined at the same address, it is replaced, keeping its position
+// within the order of registers.
+func (m *RegisterMap) Add(addr uint, name string, d Decoder) {
+	if i, ok := m.byAddr[addr]; ok {
+		m.regs[i] = register{addr, name, d}
+		return
+	}
+	m.byAddr[addr] = len(m.regs)
+	m.regs = append(m.regs, register{addr, name, d})
+}
+
+// Decode decodes the value val of the register at address addr.
+// The output is preceded by the name of the register, with the
+// lines of the register's decoder indented, like Group does.
+// An error is returned if no register is defined at the address.
+func (m *RegisterMap) Decode(addr uint, val int) ([]string, error) {
+	i, ok := m.byAddr[addr]
+	if !ok {
+		return nil, fmt.Errorf("bindec: no register at address %#x", addr)
+	}
+	r := &m.regs[i]
+	return Group(r.name, r.d).Decode(nil, val), nil
+}
+
+// Validate checks the definitions of all registers of the map, using
+// Validate and CheckNames, and reports bits not covered by any field,
+// as determined by Coverage. Problems are reported by a
+// *ValidationError, each one prefixed by the register name and
+// address, like "CTRL (0x10): ...".
+func (m *RegisterMap) Validate() error {
+	var problems []string
+	for i := range m.regs {
+		r := &m.regs[i]
+		ctx := fmt.Sprintf("%s (%#x): ", r.name, r.addr)
+		for _, err := range []error{Validate(r.d), CheckNames(r.d)} {
+			if ve, ok := err.(*ValidationError); ok {
+				for _, p := range ve.Problems {
+					problems = append(problems, ctx+p)
+				}
+			}
+		}
+		if _, uncovered := Coverage(r.d, 0); uncovered != 0 {
+			problems = append(problems, ctx+fmt.Sprintf("bits not covered: %#x", uncovered))
+		}
+	}
+	if problems != nil {
+		return &ValidationError{problems}
+	}
+	return nil
+}
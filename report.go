@@ -0,0 +1,43 @@
+package bindec
+
+import "strings"
+
+// A ReportEntry specifies a section of a Report:
+// a value, the Decoder to decode it, and a label.
+type ReportEntry struct {
+	Label   string
+	Decoder Decoder
+	Value   int
+}
+
+// Report returns a multi-register report, consisting of one section
+// per entry. Each section starts with the entry's label, followed by
+// the decoded representation of its value, indented by a tab character.
+// Sections are separated by blank lines.
+func Report(entries []ReportEntry) string {
+	return defaultOptions.Report(entries)
+}
+
+// Report is like the function Report, applying the options.
+// If Options.SkipEmpty is set, sections whose decoding doesn't
+// produce any output are omitted.
+func (o *Options) Report(entries []ReportEntry) string {
+	var b strings.Builder
+	for _, re := range entries {
+		list := o.decode(re.Decoder, nil, re.Value)
+		if len(list) == 0 && o.SkipEmpty {
+			continue
+		}
+		if b.Len() != 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(re.Label)
+		b.WriteByte('\n')
+		for i := range list {
+			list[i].depth++
+			b.WriteString(list[i].line(o))
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package bindec
+
+import "fmt"
+
+type reservedOne struct {
+	pos  uint
+	mask int
+	desc string
+}
+
+// ReservedOne defines a Decoder for reserved bits between
+// startBit and, including, endBit that must read as 1. While all these
+// bits are set, nothing will be emitted; otherwise a warning like
+// "reserved bits 4-7 expected 1: 0xb" is produced, showing the
+// actual value of the field, prefixed by desc, if not empty.
+// The bit range considers enclosing Shift decoders.
+func ReservedOne(startBit, endBit uint, desc string) Decoder {
+	return &reservedOne{startBit, bitMask(startBit, endBit), desc}
+}
+
+func (r *reservedOne) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, r, val)
+}
+
+func (r *reservedOne) decodeEntries(e []entry, val int, o *Options) []entry {
+	if val&r.mask == r.mask {
+		return e
+	}
+	b := val & r.mask >> r.pos
+	lo, hi := r.pos, endBit(r.mask)
+	if !o.opaque {
+		// positions within the top-level value
+		lo, hi = lo+o.off, hi+o.off
+	}
+	s := fmt.Sprintf("reserved bits %d-%d expected 1: %#x", lo, hi, b)
+	if r.desc == "" {
+		return append(e, entry{name: s, kind: ReservedKind, raw: b, sev: SevWarning})
+	}
+	return append(e, entry{name: r.desc, value: s, kv: true, kind: ReservedKind, raw: b, sev: SevWarning})
+}
+
+func (r *reservedOne) field() Field {
+	return Field{Name: r.desc, Kind: ReservedKind, StartBit: r.pos, EndBit: endBit(r.mask)}
+}
+
+type reserved struct {
+	pos  uint
+	mask int
+}
+
+// Reserved marks the bits between startBit and, including, endBit
+// as reserved. It doesn't produce any output, but the bits are
+// considered covered by Coverage and Validate, so that registers with
+// reserved gaps can be checked for full coverage.
+func Reserved(startBit, endBit uint) Decoder {
+	return &reserved{startBit, bitMask(startBit, endBit)}
+}
+
+func (r *reserved) Decode(w []string, val int) []string {
+	return w
+}
+
+func (r *reserved) decodeEntries(e []entry, val int, o *Options) []entry {
+	return e
+}
+
+func (r *reserved) field() Field {
+	return Field{Kind: ReservedKind, StartBit: r.pos, EndBit: endBit(r.mask)}
+}
@@ -0,0 +1,148 @@
+package bindec
+
+type fromReset struct {
+	reset int
+	d     Decoder
+}
+
+// FromReset defines a Decoder that emits only those fields of d whose
+// decoded representation differs from that of the reset value resetVal,
+// each annotated with the representation at reset, like
+// "MODE: fast (reset: MODE: slow)". This shows what has been changed
+// by firmware, for example. A signal that is set at reset, but cleared
+// now, is displayed like "!OVERTEMP (reset: OVERTEMP)", a field that
+// didn't produce output at reset like "TEMP: 20 (reset: -)".
+// Groups without differing fields are omitted.
+func FromReset(resetVal int, d Decoder) Decoder {
+	return &fromReset{resetVal, d}
+}
+
+func (r *fromReset) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, r, val)
+}
+
+func (r *fromReset) decodeEntries(e []entry, val int, o *Options) []entry {
+	cur := splitUnits(decodeEntries(r.d, nil, val, o))
+	ref := splitUnits(decodeEntries(r.d, nil, r.reset, o))
+
+	n := len(e)
+	for len(cur) != 0 || len(ref) != 0 {
+		switch {
+		case len(cur) != 0 && len(ref) != 0 && cur[0][0].src == ref[0][0].src:
+			e = appendChanged(e, cur[0], ref[0], o)
+			cur, ref = cur[1:], ref[1:]
+		case len(cur) != 0 && !containsUnit(ref, cur[0][0].src):
+			// unit missing at reset
+			e = appendChanged(e, cur[0], nil, o)
+			cur = cur[1:]
+		default:
+			// unit missing now
+			u := ref[0]
+			ref = ref[1:]
+			c := u[0]
+			switch {
+			case c.src == nil:
+				continue
+			case c.kind == GroupKind:
+				e = append(e, c)
+				continue
+			}
+			s := "-"
+			if c.isSig() {
+				s = "!" + c.keyName()
+			}
+			c.name, c.value, c.kv = s+" (reset: "+unitText(u, o, true)+")", "", false
+			e = append(e, c)
+		}
+	}
+	return pruneGroups(e, n)
+}
+
+// appendChanged appends the entries of unit u to e, combined
+// into one entry annotated with the text of ref, unless u
+// and ref produce the same text. Group headers are always appended.
+func appendChanged(e, u, ref []entry, o *Options) []entry {
+	c := u[0]
+	if c.kind == GroupKind || c.src == nil {
+		return append(e, u...)
+	}
+	resetText := "-"
+	if ref != nil {
+		if unitText(u, o, false) == unitText(ref, o, false) {
+			return e
+		}
+		resetText = unitText(ref, o, true)
+	}
+	c.name, c.value, c.kv = unitText(u, o, true)+" (reset: "+resetText+")", "", false
+	return append(e, c)
+}
+
+// splitUnits splits entries into units of consecutive entries
+// produced by the same source. Group headers form units of their own.
+func splitUnits(entries []entry) [][]entry {
+	var units [][]entry
+	for i := 0; i < len(entries); {
+		j := i + 1
+		if src := entries[i].src; src != nil && entries[i].kind != GroupKind {
+			for j < len(entries) && entries[j].src == src {
+				j++
+			}
+		}
+		units = append(units, entries[i:j])
+		i = j
+	}
+	return units
+}
+
+func containsUnit(units [][]entry, src Decoder) bool {
+	if src == nil {
+		return false
+	}
+	for _, u := range units {
+		if u[0].src == src {
+			return true
+		}
+	}
+	return false
+}
+
+// unitText returns the text of the entries of unit u;
+// if redact is set, values redacted by o are replaced.
+func unitText(u []entry, o *Options, redact bool) string {
+	s := ""
+	for i := range u {
+		if i != 0 {
+			s += "; "
+		}
+		c := u[i]
+		if redact && c.kv && o.redacted(&c) {
+			c.value = "***"
+		}
+		s += c.text(o)
+	}
+	return s
+}
+
+// pruneGroups removes group headers within e[n:] that have no children.
+func pruneGroups(e []entry, n int) []entry {
+	for {
+		out := e[:n]
+		pruned := false
+		for i := n; i < len(e); i++ {
+			c := &e[i]
+			if c.kind == GroupKind && (i+1 == len(e) || e[i+1].depth <= c.depth) {
+				pruned = true
+				continue
+			}
+			out = append(out, *c)
+		}
+		e = out
+		if !pruned {
+			return e
+		}
+	}
+}
+
+func (r *fromReset) walk(fn walkFunc, at walkPos) {
+	walk(r.d, fn, at)
+}
@@ -0,0 +1,96 @@
+package bindec
+
+import "sort"
+
+// Severity classifies the importance of a decoded field,
+// allowing to route asserted error flags to alerting, for example.
+type Severity int
+
+const (
+	SevInfo Severity = iota
+	SevWarning
+	SevError
+)
+
+var sevNames = []string{
+	SevInfo:    "info",
+	SevWarning: "warning",
+	SevError:   "error",
+}
+
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(sevNames) {
+		return sevNames[s]
+	}
+	return "severity?"
+}
+
+// SigSev defines a signal Decoder like Sig, that additionally
+// attaches a severity to the field. The severity doesn't affect
+// the output of Decode, but is reported by DecodeSev.
+// Warnings emitted by decoders like ReservedOne have
+// severity SevWarning.
+func SigSev(pos uint, name string, sev Severity) Decoder {
+	return &signal{pos: pos, mask: 1 << pos, name: name, sev: sev}
+}
+
+// A SevField is a line of decoder output together with its severity.
+type SevField struct {
+	Text     string
+	Severity Severity
+}
+
+// DecodeSev decodes val like d.Decode would do, but
+// returns each line together with the severity of its field.
+// Group headers have severity SevInfo.
+func DecodeSev(d Decoder, val int) []SevField {
+	return defaultOptions.DecodeSev(d, val)
+}
+
+// DecodeSev is like the function DecodeSev, applying the options.
+func (o *Options) DecodeSev(d Decoder, val int) []SevField {
+	entries := o.decode(d, nil, val)
+	list := make([]SevField, len(entries))
+	for i := range entries {
+		e := &entries[i]
+		list[i] = SevField{e.line(o), e.sev}
+	}
+	return list
+}
+
+// sortBySeverity sorts entries in place, by descending severity,
+// treating each group header together with its contents as a unit,
+// which itself gets sorted recursively.
+func sortBySeverity(entries []entry) {
+	if len(entries) == 0 {
+		return
+	}
+	type unit struct {
+		entries []entry
+		sev     Severity
+	}
+	var units []unit
+	depth := entries[0].depth
+	for i := 0; i < len(entries); {
+		j := i + 1
+		for j < len(entries) && entries[j].depth > depth {
+			j++
+		}
+		u := unit{entries: append([]entry(nil), entries[i:j]...)}
+		sortBySeverity(u.entries[1:])
+		for k := range u.entries {
+			if s := u.entries[k].sev; s > u.sev {
+				u.sev = s
+			}
+		}
+		units = append(units, u)
+		i = j
+	}
+	sort.SliceStable(units, func(i, j int) bool {
+		return units[i].sev > units[j].sev
+	})
+	i := 0
+	for _, u := range units {
+		i += copy(entries[i:], u.entries)
+	}
+}
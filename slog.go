@@ -0,0 +1,38 @@
+//go:build go1.21
+// +build go1.21
+
+package bindec
+
+import "log/slog"
+
+// LogValue returns the decoded representation of val, as produced by d,
+// as a group of [slog.Attr] values, like
+//
+//	slog.Any("reg", bindec.LogValue(dec, v))
+//
+// Sig and Flag fields result in boolean attributes, other fields in
+// string attributes containing the formatted values. Groups are
+// represented as nested slog groups. Lines without a value, like
+// warnings, are represented by their text being mapped to true.
+func LogValue(d Decoder, val int) slog.Value {
+	return slog.GroupValue(logAttrs(DecodeNode(d, val).Children)...)
+}
+
+func logAttrs(nodes []*Node) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(nodes))
+	for _, n := range nodes {
+		var a slog.Attr
+		switch {
+		case n.Kind == GroupKind:
+			a = slog.Attr{Key: n.Name, Value: slog.GroupValue(logAttrs(n.Children)...)}
+		case n.Kind == SigKind || n.Kind == FlagKind:
+			a = slog.Bool(n.Name, n.Value == "true")
+		case n.Value != "":
+			a = slog.String(n.Name, n.Value)
+		default:
+			a = slog.Bool(n.Text, true)
+		}
+		attrs = append(attrs, a)
+	}
+	return attrs
+}
@@ -0,0 +1,172 @@
+package bindec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+)
+
+// A FieldSpec is the data representation of a Decoder,
+// as read by a SpecLoader from JSON.
+type FieldSpec struct {
+	Kind    string      `json:"kind"` // "sig", "flag", "val", "int", "reserved", or "group"
+	Name    string      `json:"name,omitempty"`
+	Bit     uint        `json:"bit,omitempty"` // for "sig" and "flag"
+	Start   uint        `json:"start,omitempty"`
+	End     uint        `json:"end,omitempty"`
+	Names   []string    `json:"names,omitempty"`   // for "val"
+	Default string      `json:"default,omitempty"` // for "val"
+	Format  string      `json:"format,omitempty"`  // for "int"
+	Shift   uint        `json:"shift,omitempty"`
+	Fields  []FieldSpec `json:"fields,omitempty"` // for "group"
+}
+
+// A SpecLoader creates Decoders from JSON data specifications,
+// consisting of an array of FieldSpecs.
+type SpecLoader struct {
+	// If not nil, UnknownKind is called for fields of an unknown kind,
+	// and the returned Decoder is used instead, like a raw hex Int,
+	// so that a specification remains loadable even if it contains
+	// kinds not yet implemented. A warning is logged in this case.
+	// If UnknownKind is nil, unknown kinds result in an error.
+	UnknownKind func(name string, start, end uint) Decoder
+
+	// Logf is used to log warnings; if nil, log.Printf is used.
+	Logf func(format string, args ...interface{})
+}
+
+// LoadSpec reads a JSON specification from r,
+// using a SpecLoader with default settings.
+func LoadSpec(r io.Reader) (Decoder, error) {
+	return new(SpecLoader).Load(r)
+}
+
+// Load reads a JSON specification from r and
+// returns the corresponding Decoder.
+func (l *SpecLoader) Load(r io.Reader) (Decoder, error) {
+	var specs []FieldSpec
+	if err := json.NewDecoder(r).Decode(&specs); err != nil {
+		return nil, fmt.Errorf("bindec: spec: %w", err)
+	}
+	return l.Decoder(specs)
+}
+
+// Decoder returns a Decoder corresponding to the specified fields.
+func (l *SpecLoader) Decoder(specs []FieldSpec) (Decoder, error) {
+	list := make(DecoderList, 0, len(specs))
+	for i := range specs {
+		d, err := l.decoder(&specs[i])
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, nil
+}
+
+func (l *SpecLoader) decoder(s *FieldSpec) (Decoder, error) {
+	var d Decoder
+	switch s.Kind {
+	case "sig":
+		d = Sig(s.Bit, s.Name)
+	case "flag":
+		d = Flag(s.Bit, s.Name)
+	case "val":
+		d = Val(s.Start, s.End, s.Name, s.Names, s.Default)
+	case "int":
+		format := s.Format
+		if format == "" {
+			format = "%d"
+		}
+		d = Int(s.Start, s.End, s.Name, format)
+	case "reserved":
+		d = Reserved(s.Start, s.End)
+	case "group":
+		sub, err := l.Decoder(s.Fields)
+		if err != nil {
+			return nil, err
+		}
+		d = Group(s.Name, sub)
+	default:
+		if l.UnknownKind == nil {
+			return nil, fmt.Errorf("bindec: spec: field %q: unknown kind: %q", s.Name, s.Kind)
+		}
+		logf := l.Logf
+		if logf == nil {
+			logf = log.Printf
+		}
+		logf("bindec: spec: field %q: unknown kind %q, using substitute", s.Name, s.Kind)
+		d = l.UnknownKind(s.Name, s.Start, s.End)
+	}
+	if s.Shift != 0 {
+		d = Shift(s.Shift, d)
+	}
+	return d, nil
+}
+
+// A TableEntry describes a field by its mask and shift, as used by
+// FromTable: The raw field value is (val >> Shift) & Mask.
+type TableEntry struct {
+	Mask  int // contiguous, starting at bit 0
+	Shift uint
+	Kind  Kind // one of SigKind, FlagKind, ValKind, IntKind, or ReservedKind
+	Name  string
+
+	Format  string   // for IntKind; if empty, "%d" is used
+	Names   []string // for ValKind
+	Default string   // for ValKind
+}
+
+// FromTable returns a Decoder defined by a table of fields, which is
+// the low-level counterpart of LoadSpec, intended as a target for code
+// generators. The entries are checked for consistency, like masks of
+// SigKind or FlagKind fields consisting of a single bit, and fields
+// occupying the same bits; problems are reported by a *ValidationError.
+func FromTable(entries []TableEntry) (Decoder, error) {
+	var problems []string
+	list := make(DecoderList, 0, len(entries))
+	for i := range entries {
+		t := &entries[i]
+		if t.Mask <= 0 || t.Mask&(t.Mask+1) != 0 {
+			problems = append(problems, fmt.Sprintf("entry %d (%s): mask not contiguous from bit 0: %#x", i, t.Name, t.Mask))
+			continue
+		}
+		start := t.Shift
+		end := t.Shift + endBit(t.Mask)
+		var d Decoder
+		switch t.Kind {
+		case SigKind, FlagKind:
+			if t.Mask != 1 {
+				problems = append(problems, fmt.Sprintf("entry %d (%s): %v field with multi-bit mask: %#x", i, t.Name, t.Kind, t.Mask))
+				continue
+			}
+			if t.Kind == SigKind {
+				d = Sig(start, t.Name)
+			} else {
+				d = Flag(start, t.Name)
+			}
+		case ValKind:
+			d = Val(start, end, t.Name, t.Names, t.Default)
+		case IntKind:
+			format := t.Format
+			if format == "" {
+				format = "%d"
+			}
+			d = Int(start, end, t.Name, format)
+		case ReservedKind:
+			d = Reserved(start, end)
+		default:
+			problems = append(problems, fmt.Sprintf("entry %d (%s): unsupported kind: %v", i, t.Name, t.Kind))
+			continue
+		}
+		list = append(list, d)
+	}
+	if err, ok := Validate(list).(*ValidationError); ok {
+		problems = append(problems, err.Problems...)
+	}
+	if problems != nil {
+		return nil, &ValidationError{problems}
+	}
+	return list, nil
+}
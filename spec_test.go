@@ -0,0 +1,70 @@
+package bindec_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/knieriem/bindec"
+)
+
+func TestFromTable(t *testing.T) {
+	d, err := bindec.FromTable([]bindec.TableEntry{
+		{Mask: 1, Shift: 0, Kind: bindec.SigKind, Name: "EN"},
+		{Mask: 1, Shift: 1, Kind: bindec.FlagKind, Name: "ERR"},
+		{Mask: 3, Shift: 2, Kind: bindec.ValKind, Name: "MODE", Names: []string{"off", "slow"}, Default: "other"},
+		{Mask: 0xf, Shift: 4, Kind: bindec.IntKind, Name: "DIV", Format: "%#x"},
+		{Mask: 0xf, Shift: 8, Kind: bindec.IntKind, Name: "CNT"},
+		{Mask: 0xf, Shift: 12, Kind: bindec.ReservedKind},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := d.Decode(nil, 0x3ac5)
+	want := []string{"EN", "!ERR", "MODE: slow", "DIV: 0xc", "CNT: 10"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFromTableErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		entries []bindec.TableEntry
+		problem string
+	}{
+		{"zero mask", []bindec.TableEntry{
+			{Mask: 0, Kind: bindec.IntKind, Name: "A"},
+		}, "entry 0 (A): mask not contiguous from bit 0: 0x0"},
+		{"non-contiguous mask", []bindec.TableEntry{
+			{Mask: 1, Kind: bindec.SigKind, Name: "EN"},
+			{Mask: 0x5, Shift: 4, Kind: bindec.IntKind, Name: "A"},
+		}, "entry 1 (A): mask not contiguous from bit 0: 0x5"},
+		{"multi-bit sig", []bindec.TableEntry{
+			{Mask: 3, Kind: bindec.SigKind, Name: "EN"},
+		}, "entry 0 (EN): sig field with multi-bit mask: 0x3"},
+		{"multi-bit flag", []bindec.TableEntry{
+			{Mask: 3, Kind: bindec.FlagKind, Name: "ERR"},
+		}, "entry 0 (ERR): flag field with multi-bit mask: 0x3"},
+		{"unsupported kind", []bindec.TableEntry{
+			{Mask: 3, Kind: bindec.GroupKind, Name: "G"},
+		}, "entry 0 (G): unsupported kind: group"},
+		{"overlap", []bindec.TableEntry{
+			{Mask: 1, Shift: 4, Kind: bindec.SigKind, Name: "EN"},
+			{Mask: 0xf, Shift: 4, Kind: bindec.IntKind, Name: "LEN"},
+		}, "LEN (bits 4-7) overlaps EN (bit 4)"},
+	} {
+		d, err := bindec.FromTable(tc.entries)
+		if d != nil {
+			t.Errorf("%s: unexpected decoder", tc.name)
+		}
+		var verr *bindec.ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: got error %v, want *ValidationError", tc.name, err)
+			continue
+		}
+		if len(verr.Problems) != 1 || verr.Problems[0] != tc.problem {
+			t.Errorf("%s: got problems %q, want %q", tc.name, verr.Problems, tc.problem)
+		}
+	}
+}
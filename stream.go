@@ -0,0 +1,84 @@
+package bindec
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A StreamDecoder decodes fixed-width values read from a stream,
+// like a capture file of packed register samples.
+type StreamDecoder struct {
+	d     Decoder
+	order binary.ByteOrder
+	buf   []byte
+}
+
+// NewStreamDecoder returns a StreamDecoder that decodes values using d.
+// Each value consists of width/8 bytes, which are converted to an integer
+// using the specified byte order. Supported widths are 8, 16, 32, and 64;
+// other widths result in an error.
+func NewStreamDecoder(d Decoder, width uint, order binary.ByteOrder) (*StreamDecoder, error) {
+	switch width {
+	case 8, 16, 32, 64:
+	default:
+		return nil, fmt.Errorf("bindec: unsupported stream value width: %d", width)
+	}
+	return &StreamDecoder{d: d, order: order, buf: make([]byte, width/8)}, nil
+}
+
+// Next reads the next value from r and returns its decoded representation.
+// At the end of the stream, Next returns io.EOF. If the stream ends
+// in the middle of a value, io.ErrUnexpectedEOF is returned.
+func (s *StreamDecoder) Next(r io.Reader) ([]string, error) {
+	val, err := s.read(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.d.Decode(nil, val), nil
+}
+
+func (s *StreamDecoder) read(r io.Reader) (int, error) {
+	if _, err := io.ReadFull(r, s.buf); err != nil {
+		return 0, err
+	}
+	switch len(s.buf) {
+	case 1:
+		return int(s.buf[0]), nil
+	case 2:
+		return int(s.order.Uint16(s.buf)), nil
+	case 4:
+		return int(s.order.Uint32(s.buf)), nil
+	}
+	return int(s.order.Uint64(s.buf)), nil
+}
+
+// DecodeHexString decodes a value given as a string of hexadecimal
+// bytes, like "1A 53", as printed by debuggers. Spaces and underscores
+// are ignored, as well as a leading "0x". The bytes, up to eight, are
+// converted to an integer using the specified byte order.
+// An odd number of digits, or invalid digits, result in an error.
+func DecodeHexString(d Decoder, s string, order binary.ByteOrder) ([]string, error) {
+	s = strings.NewReplacer(" ", "", "_", "", "\t", "").Replace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if len(s)%2 != 0 {
+		return nil, fmt.Errorf("bindec: hex string: odd number of digits: %d", len(s))
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("bindec: hex string: %w", err)
+	}
+	if len(b) == 0 || len(b) > 8 {
+		return nil, fmt.Errorf("bindec: hex string: unsupported number of bytes: %d", len(b))
+	}
+	buf := make([]byte, 8)
+	if order.Uint16([]byte{1, 0}) == 1 {
+		// little endian: pad at the end
+		copy(buf, b)
+	} else {
+		copy(buf[8-len(b):], b)
+	}
+	return d.Decode(nil, int(order.Uint64(buf))), nil
+}
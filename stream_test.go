@@ -0,0 +1,89 @@
+package bindec_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/knieriem/bindec"
+)
+
+func TestStreamDecoder(t *testing.T) {
+	s, err := bindec.NewStreamDecoder(chanReg, 16, binary.BigEndian)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := bytes.NewReader([]byte{0x02, 0x01, 0x00})
+	got, err := s.Next(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"CH0", "\tRDY", "CH1", "\tERR"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err = s.Next(r); err != io.ErrUnexpectedEOF {
+		t.Errorf("partial value: got error %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err = s.Next(r); err != io.EOF {
+		t.Errorf("end of stream: got error %v, want %v", err, io.EOF)
+	}
+}
+
+func TestNewStreamDecoderWidth(t *testing.T) {
+	for _, width := range []uint{0, 4, 12, 24, 128} {
+		s, err := bindec.NewStreamDecoder(chanReg, width, binary.LittleEndian)
+		if err == nil || s != nil {
+			t.Errorf("width %d: no error", width)
+		}
+	}
+}
+
+func TestDecodeHexStringErrors(t *testing.T) {
+	for _, tc := range []struct {
+		s   string
+		err string
+	}{
+		{"1A 5", "bindec: hex string: odd number of digits: 3"},
+		{"0x1", "bindec: hex string: odd number of digits: 1"},
+		{"1G 53", "bindec: hex string: encoding/hex: invalid byte: U+0047 'G'"},
+		{"", "bindec: hex string: unsupported number of bytes: 0"},
+		{"0x", "bindec: hex string: unsupported number of bytes: 0"},
+		{"00 11 22 33 44 55 66 77 88", "bindec: hex string: unsupported number of bytes: 9"},
+	} {
+		_, err := bindec.DecodeHexString(chanReg, tc.s, binary.BigEndian)
+		if err == nil {
+			t.Errorf("%q: no error", tc.s)
+			continue
+		}
+		if got := err.Error(); got != tc.err {
+			t.Errorf("%q: got error %q, want %q", tc.s, got, tc.err)
+		}
+	}
+}
+
+func TestDecodeHexStringPadding(t *testing.T) {
+	for _, tc := range []struct {
+		s     string
+		order binary.ByteOrder
+		want  []string
+	}{
+		{"01", binary.BigEndian, []string{"CH0", "\tRDY"}},
+		{"01", binary.LittleEndian, []string{"CH0", "\tRDY"}},
+		{"0x02_01", binary.BigEndian, []string{"CH0", "\tRDY", "CH1", "\tERR"}},
+		{"02 01", binary.LittleEndian, []string{"CH0", "\tERR", "CH1", "\tRDY"}},
+		{"00 00 00 00 00 00 02 01", binary.BigEndian, []string{"CH0", "\tRDY", "CH1", "\tERR"}},
+		{"02\t01 00", binary.LittleEndian, []string{"CH0", "\tERR", "CH1", "\tRDY"}},
+	} {
+		got, err := bindec.DecodeHexString(chanReg, tc.s, tc.order)
+		if err != nil {
+			t.Errorf("%q: %v", tc.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q, %v: got %q, want %q", tc.s, tc.order, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package bindec
+
+import "strings"
+
+// An AnomalyError lists the anomalies found by DecodeStrict.
+type AnomalyError struct {
+	Anomalies []string
+}
+
+func (e *AnomalyError) Error() string {
+	return "bindec: " + strings.Join(e.Anomalies, "; ")
+}
+
+// DecodeStrict decodes val like d.Decode would do, but additionally
+// reports lines of severity SevWarning or higher, like those emitted by
+// ReservedOne for reserved bits not reading as 1, or a bad CRC, as
+// anomalies by an *AnomalyError. Anomalies stemming from a leaf field
+// are identified by the field, including its bit range, like
+// "CTRL.RSVD (bits 4-7): reserved bits 4-7 expected 1: 0xb".
+// The decoded lines are returned in any case.
+func DecodeStrict(d Decoder, val int) ([]string, error) {
+	return defaultOptions.DecodeStrict(d, val)
+}
+
+// DecodeStrict is like the function DecodeStrict, applying the options.
+// If FailFast is set, decoding stops at the first anomaly; the
+// lines decoded so far are returned, including the offending one.
+func (o *Options) DecodeStrict(d Decoder, val int) ([]string, error) {
+	o1 := *o
+	o1.qualify = true
+	if o.FailFast {
+		o1.failed = new(bool)
+	}
+	entries := o1.decode(d, nil, val)
+
+	var anomalies []string
+	for i := range entries {
+		e := &entries[i]
+		if e.sev < SevWarning {
+			continue
+		}
+		s := e.text(&o1)
+		if e.ref != "" {
+			if e.kv {
+				s = e.value
+			}
+			s = e.ref + ": " + s
+		}
+		anomalies = append(anomalies, s)
+		if o.FailFast {
+			break
+		}
+	}
+	w := render(nil, entries, o)
+	if anomalies != nil {
+		return w, &AnomalyError{anomalies}
+	}
+	return w, nil
+}
@@ -0,0 +1,144 @@
+package bindec
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecoderFromStruct builds a Decoder from the `bindec` tags of the fields
+// of the struct, or pointer to struct, v. A tag consists of comma
+// separated key=value pairs:
+//
+//	bit=3        the position of a single-bit field
+//	bits=4-7     the bit range of a multi-bit field
+//	name=MODE    the field name; by default the name of the struct field
+//	kind=sig     one of "sig", "flag", "val", "int", or "reserved"
+//	format=%#x   the format of an "int" field
+//	names=A|B|C  the value names of a "val" field
+//	default=X    the default name of a "val" field
+//	shift=8      moves the field, or group, to a different bit position
+//
+// Bool fields default to "flag", integer fields to "int". Fields
+// of a struct type are turned into groups containing the decoders
+// defined by their own fields. Struct fields without a tag, or with
+// a tag of "-", are ignored.
+func DecoderFromStruct(v interface{}) (Decoder, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bindec: struct: not a struct: %v", t)
+	}
+	specs, err := structSpecs(t)
+	if err != nil {
+		return nil, fmt.Errorf("bindec: struct: %w", err)
+	}
+	return new(SpecLoader).Decoder(specs)
+}
+
+func structSpecs(t reflect.Type) ([]FieldSpec, error) {
+	var specs []FieldSpec
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("bindec")
+		if !ok || tag == "-" {
+			continue
+		}
+		s, err := fieldSpec(sf, tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		specs = append(specs, s)
+	}
+	return specs, nil
+}
+
+func fieldSpec(sf reflect.StructField, tag string) (s FieldSpec, err error) {
+	s.Name = sf.Name
+	hasBits := false
+	for _, kv := range strings.Split(tag, ",") {
+		if kv == "" {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i == -1 {
+			return s, fmt.Errorf("malformed tag element: %q", kv)
+		}
+		key, val := kv[:i], kv[i+1:]
+		switch key {
+		case "bit":
+			s.Bit, err = parseBit(val)
+			s.Start, s.End = s.Bit, s.Bit
+			hasBits = true
+		case "bits":
+			j := strings.Index(val, "-")
+			if j == -1 {
+				return s, fmt.Errorf("malformed bit range: %q", val)
+			}
+			if s.Start, err = parseBit(val[:j]); err == nil {
+				s.End, err = parseBit(val[j+1:])
+			}
+			if err == nil && s.End < s.Start {
+				err = fmt.Errorf("malformed bit range: %q", val)
+			}
+			s.Bit = s.Start
+			hasBits = true
+		case "name":
+			s.Name = val
+		case "kind":
+			s.Kind = val
+		case "format":
+			s.Format = val
+		case "names":
+			s.Names = strings.Split(val, "|")
+		case "default":
+			s.Default = val
+		case "shift":
+			s.Shift, err = parseBit(val)
+		default:
+			return s, fmt.Errorf("unknown tag key: %q", key)
+		}
+		if err != nil {
+			return s, err
+		}
+	}
+
+	switch k := sf.Type.Kind(); {
+	case k == reflect.Struct:
+		if s.Kind != "" && s.Kind != "group" {
+			return s, fmt.Errorf("kind %q not applicable to struct type", s.Kind)
+		}
+		s.Kind = "group"
+		s.Fields, err = structSpecs(sf.Type)
+		return s, err
+	case s.Kind == "group":
+		return s, fmt.Errorf("kind %q requires a struct type", s.Kind)
+	case s.Kind != "":
+		switch s.Kind {
+		case "sig", "flag", "val", "int", "reserved":
+		default:
+			return s, fmt.Errorf("unknown kind: %q", s.Kind)
+		}
+	case k == reflect.Bool:
+		s.Kind = "flag"
+	case k >= reflect.Int && k <= reflect.Uint64:
+		s.Kind = "int"
+	default:
+		return s, fmt.Errorf("unsupported type: %v", sf.Type)
+	}
+	if !hasBits {
+		return s, fmt.Errorf("missing bit or bits")
+	}
+	return s, nil
+}
+
+func parseBit(s string) (uint, error) {
+	n, err := strconv.ParseUint(s, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("malformed bit position: %q", s)
+	}
+	return uint(n), nil
+}
@@ -0,0 +1,67 @@
+package bindec_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/knieriem/bindec"
+)
+
+func TestDecoderFromStructErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		v    interface{}
+		err  string
+	}{
+		{"nil", nil, "not a struct: <nil>"},
+		{"int", 5, "not a struct: int"},
+		{"tag element", struct {
+			A bool `bindec:"bit"`
+		}{}, `field A: malformed tag element: "bit"`},
+		{"unknown key", struct {
+			A bool `bindec:"bit=0,color=red"`
+		}{}, `field A: unknown tag key: "color"`},
+		{"bit position", struct {
+			A bool `bindec:"bit=x"`
+		}{}, `field A: malformed bit position: "x"`},
+		{"bit range", struct {
+			A int `bindec:"bits=4"`
+		}{}, `field A: malformed bit range: "4"`},
+		{"reversed bit range", struct {
+			A int `bindec:"bits=7-4"`
+		}{}, `field A: malformed bit range: "7-4"`},
+		{"shift", struct {
+			A int `bindec:"bit=0,shift=-1"`
+		}{}, `field A: malformed bit position: "-1"`},
+		{"missing bits", struct {
+			A int `bindec:"name=X"`
+		}{}, "field A: missing bit or bits"},
+		{"unknown kind", struct {
+			A int `bindec:"bit=0,kind=blob"`
+		}{}, `field A: unknown kind: "blob"`},
+		{"kind of struct", struct {
+			A struct{} `bindec:"kind=int"`
+		}{}, `field A: kind "int" not applicable to struct type`},
+		{"group kind", struct {
+			A int `bindec:"bit=0,kind=group"`
+		}{}, `field A: kind "group" requires a struct type`},
+		{"type", struct {
+			A string `bindec:"bit=0"`
+		}{}, "field A: unsupported type: string"},
+		{"nested", struct {
+			A struct {
+				B bool `bindec:"bits=1-2-3"`
+			} `bindec:""`
+		}{}, `field A: field B: malformed bit position: "2-3"`},
+	} {
+		_, err := bindec.DecoderFromStruct(tc.v)
+		if err == nil {
+			t.Errorf("%s: no error", tc.name)
+			continue
+		}
+		want := "bindec: struct: " + tc.err
+		if got := err.Error(); !strings.HasPrefix(got, want) {
+			t.Errorf("%s: got error %q, want %q", tc.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package bindec
+
+import "fmt"
+
+// MaxTruthTableWidth is the maximum value width accepted by TruthTable.
+const MaxTruthTableWidth = 20
+
+// A TruthRow contains a value and its decoded representation.
+type TruthRow struct {
+	Val   int
+	Lines []string
+}
+
+// TruthTable decodes each possible value of the specified width,
+// from 0 to 2^width-1, which is useful for documenting narrow fields.
+// An error is returned if width exceeds MaxTruthTableWidth.
+func TruthTable(d Decoder, width uint) ([]TruthRow, error) {
+	if width > MaxTruthTableWidth {
+		return nil, fmt.Errorf("bindec: truth table width too large: %d > %d", width, MaxTruthTableWidth)
+	}
+	rows := make([]TruthRow, 1<<width)
+	for v := range rows {
+		rows[v] = TruthRow{v, d.Decode(nil, v)}
+	}
+	return rows, nil
+}
@@ -0,0 +1,142 @@
+package bindec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Coverage returns the bits of a value of the specified width that are
+// covered by the fields of Decoder d, including reserved fields as
+// defined by Reserved and ReservedOne, and the bits that are not
+// covered. If width is zero, the width declared using WithWidth is used;
+// if no width has been declared, the width results from the highest
+// covered bit.
+func Coverage(d Decoder, width uint) (covered, uncovered int) {
+	for _, f := range Fields(d) {
+		covered |= f.Mask()
+	}
+	if width == 0 {
+		if w, ok := Width(d); ok {
+			width = w
+		} else if covered != 0 {
+			width = endBit(covered) + 1
+		}
+	}
+	all := 1<<width - 1
+	return covered & all, all &^ covered
+}
+
+// A ValidationError lists the problems found by Validate.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "bindec: " + strings.Join(e.Problems, "; ")
+}
+
+// A validator is implemented by leaf Decoders that are able
+// to check their own definition, like the ranges of ValRanges.
+type validator interface {
+	validate() []string
+}
+
+// Validate checks the definition of Decoder d for fields
+// occupying the same bits, except for fields that are subject to
+// different conditional decoders, like alternatives selected using
+// WhenState or Optional, and, in case a width has been
+// declared using WithWidth, for fields exceeding the width.
+// Leaf decoders are checked for inconsistencies as well,
+// like overlapping ranges of a ValRanges.
+// Problems are reported by a *ValidationError.
+func Validate(d Decoder) error {
+	var problems []string
+
+	var fields []Field
+	var pos []walkPos
+	walk(d, func(l leaf, at walkPos) {
+		f := absField(l, at)
+		if v, ok := l.(validator); ok {
+			for _, p := range v.validate() {
+				problems = append(problems, fieldRef(&f)+": "+p)
+			}
+		}
+		fields = append(fields, f)
+		pos = append(pos, at)
+	}, walkPos{})
+	w, hasWidth := Width(d)
+	for i := range fields {
+		f := &fields[i]
+		for j := range fields[:i] {
+			if pos[i].exclusive(pos[j]) {
+				continue
+			}
+			if g := &fields[j]; f.Mask()&g.Mask() != 0 {
+				problems = append(problems, fmt.Sprintf("%s overlaps %s", fieldRef(f), fieldRef(g)))
+			}
+		}
+		if hasWidth && f.EndBit >= w {
+			problems = append(problems, fmt.Sprintf("%s exceeds width %d", fieldRef(f), w))
+		}
+	}
+	if problems != nil {
+		return &ValidationError{problems}
+	}
+	return nil
+}
+
+// CheckNames checks that the names of the fields and groups defined
+// by Decoder d, including prefixes defined by Prefix, are unique
+// within the whole tree, so that they can be addressed by name, like
+// using ExtractInt. Groups and fields share the same namespace.
+// Duplicates are reported by a *ValidationError, listing the
+// full path of each occurrence.
+func CheckNames(d Decoder) error {
+	var names []string // in order of first occurrence
+	refs := make(map[string][]string)
+	add := func(name, ref string) {
+		if refs[name] == nil {
+			names = append(names, name)
+		}
+		refs[name] = append(refs[name], ref)
+	}
+	seen := make(map[Decoder]bool)
+	walk(d, func(l leaf, at walkPos) {
+		for i, g := range at.owners {
+			if !seen[g] {
+				seen[g] = true
+				add(at.groups[i], strings.Join(at.groups[:i+1], ".")+" (group)")
+			}
+		}
+		f := absField(l, at)
+		if f.Name != "" && f.Name != "<reserved>" {
+			add(f.Name, fieldRef(&f))
+		}
+	}, walkPos{})
+
+	var problems []string
+	for _, name := range names {
+		if r := refs[name]; len(r) > 1 {
+			problems = append(problems, fmt.Sprintf("duplicate name %q: %s", name, strings.Join(r, ", ")))
+		}
+	}
+	if problems != nil {
+		return &ValidationError{problems}
+	}
+	return nil
+}
+
+// fieldRef returns a string identifying a field in error messages.
+func fieldRef(f *Field) string {
+	name := f.Name
+	if name == "" {
+		name = f.Kind.String()
+	}
+	if len(f.Groups) != 0 {
+		name = strings.Join(f.Groups, ".") + "." + name
+	}
+	if f.StartBit == f.EndBit {
+		return fmt.Sprintf("%s (bit %d)", name, f.StartBit)
+	}
+	return fmt.Sprintf("%s (bits %d-%d)", name, f.StartBit, f.EndBit)
+}
@@ -0,0 +1,655 @@
+package bindec
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValBits is like Val, but appends the bit pattern of the field,
+// zero-padded to the width of the field, to the value name,
+// like in "MODE: FAST (0b10)".
+func ValBits(startBit, endBit uint, desc string, names []string, dflt string) Decoder {
+	return &value{pos: startBit, mask: bitMask(startBit, endBit), desc: desc, names: names, dflt: dflt, showBits: true}
+}
+
+// ValDuration is like Val, but maps the value between startBit and,
+// including, endBit to one of the specified durations, which is
+// formatted using [time.Duration.String], like "100ms". A zero
+// duration is displayed as "disabled".
+func ValDuration(startBit, endBit uint, desc string, durations []time.Duration, dflt string) Decoder {
+	names := make([]string, len(durations))
+	for i, d := range durations {
+		if d == 0 {
+			names[i] = "disabled"
+		} else {
+			names[i] = d.String()
+		}
+	}
+	return Val(startBit, endBit, desc, names, dflt)
+}
+
+// ValConst is like Val, but prepends prefix to each of the names
+// in constNames, so that the output matches the identifiers of
+// generated Go constants, like "ModeFast" for prefix "Mode" and
+// name "Fast". Values not covered by constNames are displayed
+// the way a generated String method would do, like "Mode(5)".
+func ValConst(startBit, endBit uint, desc string, constNames []string, prefix string) Decoder {
+	names := make([]string, len(constNames))
+	for i, name := range constNames {
+		if name != "" {
+			names[i] = prefix + name
+		}
+	}
+	return &value{
+		pos:   startBit,
+		mask:  bitMask(startBit, endBit),
+		desc:  desc,
+		names: names,
+		unknown: func(b int) string {
+			return prefix + "(" + strconv.Itoa(b) + ")"
+		},
+	}
+}
+
+type indexed struct {
+	selPos  uint
+	selMask int
+	pos     uint
+	mask    int
+	desc    string
+	tables  [][]string
+}
+
+// Indexed defines a value field Decoder for fields whose
+// meaning depends on a selector field. The value between
+// selectStart and, including, selectEnd selects one of the
+// names tables; the value between fieldStart and fieldEnd is then
+// mapped to the corresponding element of that table, like Val
+// would do. If either the selector or the field value is out of
+// range, the field value is displayed as a decimal number.
+func Indexed(selectStart, selectEnd, fieldStart, fieldEnd uint, desc string, tables [][]string) Decoder {
+	return &indexed{
+		selPos:  selectStart,
+		selMask: bitMask(selectStart, selectEnd),
+		pos:     fieldStart,
+		mask:    bitMask(fieldStart, fieldEnd),
+		desc:    desc,
+		tables:  tables,
+	}
+}
+
+func (v *indexed) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *indexed) decodeEntries(e []entry, val int, o *Options) []entry {
+	sel := val & v.selMask >> v.selPos
+	b := val & v.mask >> v.pos
+
+	s := strconv.Itoa(b)
+	if sel < len(v.tables) {
+		if names := v.tables[sel]; b < len(names) {
+			s = names[b]
+		}
+	}
+	if s == "" {
+		return e
+	}
+	if v.desc == "" {
+		return append(e, entry{name: s, kind: ValKind, raw: b})
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: ValKind, raw: b})
+}
+
+func (v *indexed) field() Field {
+	return Field{Name: v.desc, Kind: ValKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+type oneHot struct {
+	pos   uint
+	mask  int
+	desc  string
+	names []string
+}
+
+// OneHot defines a Decoder for one-hot encoded fields, where exactly one
+// of the bits between startBit and, including, endBit is expected to be set,
+// as is common for interrupt vector or priority registers. The position of
+// the set bit, relative to startBit, is mapped to the corresponding element
+// of names, or to "bitN", if names is too short. If no bit, or more than
+// one bit is set, a warning is emitted instead.
+func OneHot(startBit, endBit uint, desc string, names []string) Decoder {
+	return &oneHot{startBit, bitMask(startBit, endBit), desc, names}
+}
+
+func (v *oneHot) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *oneHot) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := val & v.mask >> v.pos
+
+	var s string
+	sev := SevWarning
+	switch {
+	case b == 0:
+		s = "WARNING: no bit set"
+	case b&(b-1) != 0:
+		s = fmt.Sprintf("WARNING: multiple bits set: %#x", b)
+	default:
+		sev = SevInfo
+		i := bits.TrailingZeros(uint(b))
+		if i < len(v.names) && v.names[i] != "" {
+			s = v.names[i]
+		} else {
+			s = "bit" + strconv.Itoa(i)
+		}
+	}
+	if v.desc == "" {
+		return append(e, entry{name: s, kind: ValKind, raw: b, sev: sev})
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: ValKind, raw: b, sev: sev})
+}
+
+func (v *oneHot) field() Field {
+	return Field{Name: v.desc, Kind: ValKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+// RepeatVal defines count consecutive value fields of fieldBits bits
+// each, starting at startBit, that are mapped through the same names
+// and dflt, like Val does. The description of the i-th field, counting
+// from zero, is formatted using [fmt.Sprintf](descFmt, i),
+// e.g. "MODE%d". See Options.RunFormat for collapsing runs of
+// equal values.
+func RepeatVal(startBit, fieldBits, count uint, descFmt string, names []string, dflt string) Decoder {
+	return newRepeat(startBit, fieldBits, count, func(start, end uint, i uint) Decoder {
+		return Val(start, end, fmt.Sprintf(descFmt, i), names, dflt)
+	})
+}
+
+// Repeat is the integer counterpart of RepeatVal: It defines count
+// consecutive integer fields of fieldBits bits each, starting at
+// startBit, that are formatted like Int does.
+func Repeat(startBit, fieldBits, count uint, descFmt string, format string) Decoder {
+	return newRepeat(startBit, fieldBits, count, func(start, end uint, i uint) Decoder {
+		return Int(start, end, fmt.Sprintf(descFmt, i), format)
+	})
+}
+
+type repeat struct {
+	list DecoderList
+}
+
+func newRepeat(startBit, fieldBits, count uint, elem func(start, end uint, i uint) Decoder) *repeat {
+	list := make(DecoderList, 0, count)
+	for i := uint(0); i < count; i++ {
+		pos := startBit + i*fieldBits
+		list = append(list, elem(pos, pos+fieldBits-1, i))
+	}
+	return &repeat{list}
+}
+
+func (r *repeat) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, r, val)
+}
+
+func (r *repeat) decodeEntries(e []entry, val int, o *Options) []entry {
+	if o.RunFormat == "" {
+		return r.list.decodeEntries(e, val, o)
+	}
+	inRun := false // the last entry may be extended by the next element
+	first := ""
+	for _, d := range r.list {
+		m := len(e)
+		e = decodeEntries(d, e, val, o)
+		if len(e) != m+1 {
+			inRun = false
+			continue
+		}
+		c := &e[m]
+		if inRun {
+			if prev := &e[m-1]; prev.kv && c.kv && prev.value == c.value {
+				prev.name = fmt.Sprintf(o.RunFormat, first, c.name)
+				prev.key = prev.name
+				prev.mask |= c.mask
+				if prev.bits != "" {
+					run := Field{StartBit: uint(bits.TrailingZeros(uint(prev.mask))), EndBit: endBit(prev.mask)}
+					prev.bits = bitsLabel(&run)
+				}
+				e = e[:m]
+				continue
+			}
+		}
+		first = c.name
+		inRun = true
+	}
+	return e
+}
+
+func (r *repeat) walk(fn walkFunc, at walkPos) {
+	r.list.walk(fn, at)
+}
+
+// A ValRange maps the values from Lo to, including, Hi to Name.
+type ValRange struct {
+	Lo, Hi int
+	Name   string
+}
+
+type valRanges struct {
+	pos    uint
+	mask   int
+	desc   string
+	ranges []ValRange
+}
+
+// ValRanges defines a value field Decoder for range-quantized fields.
+// The value between startBit and, including, endBit is mapped
+// to the name of the range containing it; if no range contains
+// the value, it is displayed as a decimal number.
+// Overlapping ranges are reported by Validate.
+func ValRanges(startBit, endBit uint, desc string, ranges []ValRange) Decoder {
+	return &valRanges{startBit, bitMask(startBit, endBit), desc, ranges}
+}
+
+func (v *valRanges) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *valRanges) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := val & v.mask >> v.pos
+
+	s := strconv.Itoa(b)
+	for _, r := range v.ranges {
+		if b >= r.Lo && b <= r.Hi {
+			s = r.Name
+			break
+		}
+	}
+	if v.desc == "" {
+		return append(e, entry{name: s, kind: ValKind, raw: b})
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: ValKind, raw: b})
+}
+
+func (v *valRanges) field() Field {
+	return Field{Name: v.desc, Kind: ValKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+func (v *valRanges) validate() []string {
+	var problems []string
+	for i, r := range v.ranges {
+		if r.Lo > r.Hi {
+			problems = append(problems, fmt.Sprintf("range %s (%d-%d) is empty", r.Name, r.Lo, r.Hi))
+		}
+		for _, q := range v.ranges[:i] {
+			if r.Lo <= q.Hi && q.Lo <= r.Hi {
+				problems = append(problems, fmt.Sprintf("range %s (%d-%d) overlaps %s (%d-%d)", r.Name, r.Lo, r.Hi, q.Name, q.Lo, q.Hi))
+			}
+		}
+	}
+	return problems
+}
+
+type pin struct {
+	dirPos uint
+	valPos uint
+	name   string
+}
+
+// PinConfig defines a Decoder for count GPIO pins, each being configured
+// by a direction bit at position dirBase+i, where 1 means output, and
+// a value bit at position valBase+i. Each pin decodes to a line like
+// "P3: output=1"; the pin name is formatted using
+// [fmt.Sprintf](nameFmt, i), e.g. "P%d".
+func PinConfig(dirBase, valBase uint, count uint, nameFmt string) Decoder {
+	list := make(DecoderList, 0, count)
+	for i := uint(0); i < count; i++ {
+		list = append(list, &pin{dirBase + i, valBase + i, fmt.Sprintf(nameFmt, i)})
+	}
+	return list
+}
+
+func (p *pin) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, p, val)
+}
+
+func (p *pin) decodeEntries(e []entry, val int, o *Options) []entry {
+	dir := "input"
+	if val>>p.dirPos&1 != 0 {
+		dir = "output"
+	}
+	b := val >> p.valPos & 1
+	s := dir + "=" + strconv.Itoa(b)
+	return append(e, entry{name: p.name, value: s, kv: true, kind: ValKind, raw: b})
+}
+
+func (p *pin) field() Field {
+	mask := 1<<p.dirPos | 1<<p.valPos
+	lo, hi := p.dirPos, p.valPos
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	return Field{Name: p.name, Kind: ValKind, StartBit: lo, EndBit: hi, mask: mask}
+}
+
+func (p *pin) extract(val int) int {
+	return val>>p.dirPos&1<<1 | val>>p.valPos&1
+}
+
+type valMap struct {
+	pos     uint
+	mask    int
+	desc    string
+	names   map[int]string
+	aliases map[int]int
+	dflt    string
+}
+
+// ValMap is like Val, but maps the value between startBit and,
+// including, endBit using a map, which is more convenient than a
+// slice if the defined values are sparse, like 0x00, 0x10, and 0xFF.
+// Values not contained in names are mapped to dflt; if it is empty,
+// they are not displayed.
+func ValMap(startBit, endBit uint, desc string, names map[int]string, dflt string) Decoder {
+	return &valMap{pos: startBit, mask: bitMask(startBit, endBit), desc: desc, names: names, dflt: dflt}
+}
+
+// ValAlias is like ValMap, for fields having redundant encodings:
+// Codes contained in aliases are mapped to a code of canonical first,
+// which is then used to look up the name. Assemble uses
+// the canonical codes.
+func ValAlias(startBit, endBit uint, desc string, canonical map[int]string, aliases map[int]int, dflt string) Decoder {
+	return &valMap{pos: startBit, mask: bitMask(startBit, endBit), desc: desc, names: canonical, aliases: aliases, dflt: dflt}
+}
+
+// lookup returns the name of code b.
+func (v *valMap) lookup(b int) (string, bool) {
+	if c, ok := v.aliases[b]; ok {
+		b = c
+	}
+	s, ok := v.names[b]
+	return s, ok
+}
+
+func (v *valMap) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *valMap) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := val & v.mask >> v.pos
+
+	s, ok := v.lookup(b)
+	if !ok {
+		s = v.dflt
+	}
+	if s == "" {
+		return e
+	}
+	if v.desc == "" {
+		return append(e, entry{name: s, kind: ValKind, raw: b})
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: ValKind, raw: b})
+}
+
+func (v *valMap) field() Field {
+	return Field{Name: v.desc, Kind: ValKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+func (v *valMap) encode(s string) (int, error) {
+	for b, name := range v.names {
+		if name == s && name != "" {
+			return b, nil
+		}
+	}
+	i, err := strconv.ParseInt(s, 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("unknown value: %q", s)
+	}
+	return int(i), nil
+}
+
+func (v *valMap) valueString(raw int) string {
+	if name, _ := v.lookup(raw); name != "" {
+		return name
+	}
+	return strconv.Itoa(raw)
+}
+
+type combo struct {
+	bits  []uint
+	mask  int
+	desc  string
+	names map[int]string
+	dflt  string
+}
+
+// ComboVal defines a value field Decoder for mode encodings spread
+// across bits that are not necessarily adjacent. The listed bits
+// are gathered into a code, with the first bit forming the most
+// significant bit, which is mapped to a name like ValMap does.
+// For instance, with bits {5, 0}, a value having both bits
+// set results in code 3.
+func ComboVal(bits []uint, desc string, names map[int]string, dflt string) Decoder {
+	c := &combo{bits: bits, desc: desc, names: names, dflt: dflt}
+	for _, b := range bits {
+		c.mask |= 1 << b
+	}
+	return c
+}
+
+func (c *combo) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, c, val)
+}
+
+func (c *combo) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := c.extract(val)
+
+	s, ok := c.names[b]
+	if !ok {
+		s = c.dflt
+	}
+	if s == "" {
+		return e
+	}
+	if c.desc == "" {
+		return append(e, entry{name: s, kind: ValKind, raw: b})
+	}
+	return append(e, entry{name: c.desc, value: s, kv: true, kind: ValKind, raw: b})
+}
+
+func (c *combo) extract(val int) int {
+	b := 0
+	for _, pos := range c.bits {
+		b = b<<1 | val>>pos&1
+	}
+	return b
+}
+
+func (c *combo) field() Field {
+	return Field{Name: c.desc, Kind: ValKind, StartBit: uint(bits.TrailingZeros(uint(c.mask))), EndBit: endBit(c.mask), mask: c.mask}
+}
+
+type lane struct {
+	pos  uint
+	mask int
+	d    Decoder
+}
+
+// Lanes defines a Decoder for values consisting of count lanes of
+// laneBits bits each, all having the same layout, like per-channel
+// status bytes. Each lane, starting with the least significant one,
+// is decoded by d as a group, with the lane's bits shifted to bit 0,
+// and all other bits cleared. The group name is formatted using
+// [fmt.Sprintf](labelFmt, i), e.g. "LANE%d".
+func Lanes(laneBits, count uint, labelFmt string, d Decoder) Decoder {
+	list := make(DecoderList, 0, count)
+	for i := uint(0); i < count; i++ {
+		list = append(list, Group(fmt.Sprintf(labelFmt, i), &lane{i * laneBits, 1<<laneBits - 1, d}))
+	}
+	return list
+}
+
+func (l *lane) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, l, val)
+}
+
+func (l *lane) decodeEntries(e []entry, val int, o *Options) []entry {
+	lo := *o
+	lo.off += l.pos
+	return decodeEntries(l.d, e, val>>l.pos&l.mask, &lo)
+}
+
+func (l *lane) walk(fn walkFunc, at walkPos) {
+	walk(l.d, fn, at.shifted(l.pos))
+}
+
+type flagsField struct {
+	pos   uint
+	mask  int
+	desc  string
+	names []string
+}
+
+// FlagsField defines a Decoder for a field between startBit and,
+// including, endBit, whose bits are flags, like capabilities. The names
+// of the set flags, taken from names, which is indexed by the bit
+// position within the field, are joined by "|" into one line, like
+// "CAPS: DMA|IRQ|bit5"; set bits without a name are displayed like
+// "bit5". If no bit is set, nothing is emitted.
+func FlagsField(startBit, endBit uint, desc string, names []string) Decoder {
+	return &flagsField{startBit, bitMask(startBit, endBit), desc, names}
+}
+
+func (v *flagsField) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *flagsField) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := val & v.mask >> v.pos
+	if b == 0 {
+		return e
+	}
+	s := ""
+	for i := 0; b>>uint(i) != 0; i++ {
+		if b>>uint(i)&1 == 0 {
+			continue
+		}
+		if s != "" {
+			s += "|"
+		}
+		if i < len(v.names) && v.names[i] != "" {
+			s += v.names[i]
+		} else {
+			s += "bit" + strconv.Itoa(i)
+		}
+	}
+	if v.desc == "" {
+		return append(e, entry{name: s, kind: ValKind, raw: b})
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: ValKind, raw: b})
+}
+
+func (v *flagsField) field() Field {
+	return Field{Name: v.desc, Kind: ValKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+type stringerVal struct {
+	pos  uint
+	mask int
+	desc string
+	f    func(int) (fmt.Stringer, bool)
+}
+
+// ValStringerChecked defines a value field Decoder for fields
+// corresponding to a Go enum type having a String method, like one
+// generated by stringer. The value between startBit and, including,
+// endBit is passed to f, which returns the enum value, and whether
+// it is valid. Valid values are displayed using their String method,
+// invalid ones like "MODE: 7 (invalid)".
+func ValStringerChecked(startBit, endBit uint, desc string, f func(int) (fmt.Stringer, bool)) Decoder {
+	return &stringerVal{startBit, bitMask(startBit, endBit), desc, f}
+}
+
+func (v *stringerVal) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *stringerVal) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := val & v.mask >> v.pos
+
+	var s string
+	sev := SevInfo
+	if x, ok := v.f(b); ok && x != nil {
+		s = x.String()
+	} else {
+		s = strconv.Itoa(b) + " (invalid)"
+		sev = SevWarning
+	}
+	if v.desc == "" {
+		return append(e, entry{name: s, kind: ValKind, raw: b, sev: sev})
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: ValKind, raw: b, sev: sev})
+}
+
+func (v *stringerVal) field() Field {
+	return Field{Name: v.desc, Kind: ValKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
+
+type cBitmask struct {
+	pos   uint
+	mask  int
+	desc  string
+	names map[int]string
+}
+
+// CBitmask defines a Decoder for a field between startBit and,
+// including, endBit, whose value is a combination of flags, formatted
+// the way C code would print a bitmask type, like "FLAG_A | FLAG_B".
+// The keys of names are the values of the single flags within the
+// field, like 0x1 for "FLAG_A", and 0x2 for "FLAG_B". Set bits without
+// a name are combined into a hexadecimal residue, like
+// "FLAG_A | 0x30"; a value of zero is displayed as "0".
+func CBitmask(startBit, endBit uint, desc string, names map[int]string) Decoder {
+	return &cBitmask{startBit, bitMask(startBit, endBit), desc, names}
+}
+
+func (v *cBitmask) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, v, val)
+}
+
+func (v *cBitmask) decodeEntries(e []entry, val int, o *Options) []entry {
+	b := val & v.mask >> v.pos
+
+	var parts []string
+	rest := 0
+	for i := uint(0); b>>i != 0; i++ {
+		bit := 1 << i
+		if b&bit == 0 {
+			continue
+		}
+		if name, ok := v.names[bit]; ok {
+			parts = append(parts, name)
+		} else {
+			rest |= bit
+		}
+	}
+	if rest != 0 {
+		parts = append(parts, fmt.Sprintf("%#x", rest))
+	}
+	s := "0"
+	if parts != nil {
+		s = strings.Join(parts, " | ")
+	}
+	if v.desc == "" {
+		return append(e, entry{name: s, kind: ValKind, raw: b})
+	}
+	return append(e, entry{name: v.desc, value: s, kv: true, kind: ValKind, raw: b})
+}
+
+func (v *cBitmask) field() Field {
+	return Field{Name: v.desc, Kind: ValKind, StartBit: v.pos, EndBit: endBit(v.mask)}
+}
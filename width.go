@@ -0,0 +1,64 @@
+package bindec
+
+import "fmt"
+
+type width struct {
+	width uint
+	d     Decoder
+}
+
+// WithWidth annotates Decoder d with the width in bits of the values
+// it is intended to decode. The width can be queried using Width.
+// If Options.WarnWidth is set, decoding a value with bits set
+// beyond the width results in a warning.
+func WithWidth(w uint, d Decoder) Decoder {
+	return &width{w, d}
+}
+
+// Width returns the width of the values a Decoder is intended to
+// decode, as declared by WithWidth. The declaration may be wrapped
+// by Group decoders. If no width has been declared, ok is false.
+func Width(d Decoder) (w uint, ok bool) {
+	for {
+		switch x := d.(type) {
+		case *width:
+			return x.width, true
+		case *group:
+			d = x.d
+		default:
+			return 0, false
+		}
+	}
+}
+
+func (x *width) Decode(w []string, val int) []string {
+	return defaultOptions.Decode(w, x, val)
+}
+
+func (x *width) decodeEntries(e []entry, val int, o *Options) []entry {
+	e = decodeEntries(x.d, e, val, o)
+	if o.WarnWidth {
+		if excess := val &^ (1<<x.width - 1); excess != 0 {
+			e = append(e, entry{name: fmt.Sprintf("WARNING: bits set beyond width %d: %#x", x.width, excess), sev: SevWarning})
+		}
+	}
+	return e
+}
+
+func (x *width) walk(fn walkFunc, at walkPos) {
+	walk(x.d, fn, at)
+}
+
+// MaxBit returns the highest bit position touched by any field of
+// Decoder d, considering enclosing Shift decoders, which can be used
+// to determine whether a value of a certain width suffices to hold
+// all fields. For a Decoder without fields, the result is 0.
+func MaxBit(d Decoder) uint {
+	max := uint(0)
+	walk(d, func(l leaf, at walkPos) {
+		if f := absField(l, at); f.EndBit > max {
+			max = f.EndBit
+		}
+	}, walkPos{})
+	return max
+}